@@ -24,3 +24,13 @@ func NewDefaultMySqlFactory() *DefaultMySqlFactory {
 
 	return c
 }
+
+// RegisterPersistence registers a constructor of a persistence component
+// so it can be created by the factory through its descriptor.
+//	Parameters:
+//		- descriptor a locator to identify the persistence component.
+//		- constructor a function without parameters that returns a new persistence instance,
+//			e.g. func NewMyMySqlPersistence() *MyMySqlPersistence
+func (c *DefaultMySqlFactory) RegisterPersistence(descriptor *cref.Descriptor, constructor any) {
+	c.RegisterType(descriptor, constructor)
+}
@@ -0,0 +1,81 @@
+package connect
+
+import (
+	"sync"
+	"time"
+)
+
+// MySqlCircuitBreaker stops calls to the database after a number of consecutive
+// connection failures so they fail fast instead of waiting for connection timeouts.
+// After the cooldown period a single probe call is allowed: the breaker closes
+// when it succeeds and opens for another cooldown period when it fails.
+type MySqlCircuitBreaker struct {
+	lock      sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewMySqlCircuitBreaker creates a new instance of the circuit breaker.
+//	Parameters:
+//		- threshold a number of consecutive connection failures to open the breaker
+//		- cooldown a time to fail calls before the next probe
+//	Returns: created circuit breaker
+func NewMySqlCircuitBreaker(threshold int, cooldown time.Duration) *MySqlCircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &MySqlCircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow checks if a call to the database can be made.
+//	Returns: true if the breaker is closed or the call is a probe after the cooldown and false otherwise.
+func (c *MySqlCircuitBreaker) Allow() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.failures < c.threshold {
+		return true
+	}
+	if c.probing || time.Since(c.openedAt) < c.cooldown {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// Success records a successful call and closes the breaker.
+func (c *MySqlCircuitBreaker) Success() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.failures = 0
+	c.probing = false
+}
+
+// Failure records a connection failure and opens the breaker
+// when the number of consecutive failures reaches the threshold.
+func (c *MySqlCircuitBreaker) Failure() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.failures++
+	if c.failures >= c.threshold {
+		c.openedAt = time.Now()
+	}
+	c.probing = false
+}
+
+// IsOpen checks if calls to the database are stopped.
+//	Returns: true if the breaker is opened and false otherwise.
+func (c *MySqlCircuitBreaker) IsOpen() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.failures >= c.threshold
+}
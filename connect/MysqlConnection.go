@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"math"
+	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -19,6 +20,8 @@ import (
 //
 // By defining a connection and sharing it through multiple persistence components
 // you can reduce number of used database connections.
+// Persistence components that create their own connections pass their options to it,
+// so the defaults of the persistence are used instead of the connection defaults.
 //
 //	Configuration parameters
 //		- connection(s):
@@ -31,9 +34,19 @@ import (
 //			- username:             user name
 //			- password:             user password
 //		- options:
-//			- connect_timeout:      (optional) number of milliseconds to wait before timing out when connecting a new client (default: 0)
+//			- connect_timeout:      (optional) number of milliseconds to wait before timing out when connecting a new client (default: 1000)
 //			- idle_timeout:         (optional) number of milliseconds a client must sit idle in the pool and not be checked out (default: 10000)
-//			- max_pool_size:        (optional) maximum number of clients the pool should contain (default: 10)
+//			- max_pool_size:        (optional) maximum number of clients the pool should contain (default: 3)
+//			- max_retries:          (optional) number of attempts to connect before giving up (default: 3)
+//			- retry_backoff_ms:     (optional) base number of milliseconds to wait between connection attempts (default: 1000)
+//			- max_retry_backoff_ms: (optional) maximum number of milliseconds to wait between connection attempts (default: 30000)
+//			- warmup_connections:   (optional) number of connections to open in the pool on opening, capped by max_pool_size (default: 0)
+//			- circuit_breaker_threshold:   (optional) number of consecutive connection failures to fail fast, 0 to disable the breaker (default: 0)
+//			- circuit_breaker_cooldown_ms: (optional) number of milliseconds to fail fast before the next attempt (default: 30000)
+//			- interpolate_params:   (optional) interpolate query parameters in the driver instead of preparing statements on the server, see MySqlConnectionResolver (default: false)
+//			- sql_mode:             (optional) SQL mode set in sessions of all pooled connections, see MySqlConnectionResolver (default: the server mode)
+//			- timezone:             (optional) time zone to write time.Time values and read DATETIME values in, see MySqlConnectionResolver (default: UTC)
+//			- application_name:     (optional) name of the application to identify connections on the server, see MySqlConnectionResolver
 //
 //	References
 //		- *:logger:*:*:1.0           (optional) ILogger components to pass log messages
@@ -47,19 +60,39 @@ type MySqlConnection struct {
 	ConnectionResolver *MySqlConnectionResolver
 	// The configuration options.
 	Options *cconf.ConfigParams
-	// The MySQL connection pool object.
+	// The MySQL connection pool object. It is replaced by Reconnect, so it shall be read with GetConnection.
 	Connection *sql.DB
 	// The MySQL database name.
 	DatabaseName string
+	// Called after the connection pool is opened, e.g. to set session variables.
+	// When it returns an error the pool is closed and the opening fails.
+	OnOpen func(ctx context.Context, correlationId string, pool *sql.DB) error
+	// Called after the connection pool is closed.
+	OnClose func(ctx context.Context, correlationId string)
+	// Called when the connection fails to open after all attempts.
+	OnError func(ctx context.Context, correlationId string, err error)
 
-	retries int
+	// Guards the connection pool and the connection string replaced on reconnecting
+	poolLock sync.RWMutex
+	// Connection string resolved on opening and reused on reconnecting
+	uri             string
+	retries         int
+	retryBackoff    int
+	maxRetryBackoff int
+	// Circuit breaker shared by persistence components, nil when disabled
+	circuitBreaker *MySqlCircuitBreaker
 }
 
 const (
-	DefaultConnectTimeout = 1000
-	DefaultIdleTimeout    = 10000
-	DefaultMaxPoolSize    = 3
-	DefaultRetriesCount   = 3
+	DefaultConnectTimeout  = 1000
+	DefaultIdleTimeout     = 10000
+	DefaultMaxPoolSize     = 3
+	DefaultRetriesCount    = 3
+	DefaultRetryBackoff    = 1000
+	DefaultMaxRetryBackoff = 30000
+	DefaultBreakerCooldown = 30000
+	// Default number of idle connections kept by database/sql
+	DefaultMaxIdleConns = 2
 )
 
 // NewMySqlConnection creates a new instance of the connection component.
@@ -74,6 +107,8 @@ func NewMySqlConnection() *MySqlConnection {
 		ConnectionResolver: NewMySqlConnectionResolver(),
 		Options:            cconf.NewEmptyConfigParams(),
 		retries:            DefaultRetriesCount,
+		retryBackoff:       DefaultRetryBackoff,
+		maxRetryBackoff:    DefaultMaxRetryBackoff,
 	}
 	return c
 }
@@ -87,6 +122,21 @@ func (c *MySqlConnection) Configure(ctx context.Context, config *cconf.ConfigPar
 	c.ConnectionResolver.Configure(ctx, config)
 	c.Options = c.Options.Override(config.GetSection("options"))
 
+	c.retries = c.Options.GetAsIntegerWithDefault("max_retries", c.retries)
+	if c.retries < 1 {
+		c.retries = 1
+	}
+	c.retryBackoff = c.Options.GetAsIntegerWithDefault("retry_backoff_ms", c.retryBackoff)
+	c.maxRetryBackoff = c.Options.GetAsIntegerWithDefault("max_retry_backoff_ms", c.maxRetryBackoff)
+
+	breakerThreshold := c.Options.GetAsIntegerWithDefault("circuit_breaker_threshold", 0)
+	if breakerThreshold > 0 {
+		breakerCooldown := c.Options.GetAsIntegerWithDefault("circuit_breaker_cooldown_ms", DefaultBreakerCooldown)
+		c.circuitBreaker = NewMySqlCircuitBreaker(breakerThreshold, time.Duration(breakerCooldown)*time.Millisecond)
+	} else {
+		c.circuitBreaker = nil
+	}
+
 	c.DatabaseName, _ = config.GetAsNullableString("connection.database")
 }
 
@@ -102,7 +152,7 @@ func (c *MySqlConnection) SetReferences(ctx context.Context, references cref.IRe
 // IsOpen checks if the component is opened.
 //	Returns true if the component has been opened and false otherwise.
 func (c *MySqlConnection) IsOpen() bool {
-	return c.Connection != nil
+	return c.GetConnection() != nil
 }
 
 //	Open the component.
@@ -115,38 +165,120 @@ func (c *MySqlConnection) Open(ctx context.Context, correlationId string) error
 	uri, err := c.ConnectionResolver.Resolve(ctx, correlationId)
 	if err != nil {
 		c.Logger.Error(ctx, correlationId, err, "Failed to resolve MySql connection")
-		return nil
+		err = cerr.
+			NewConnectionError(correlationId, "CONNECT_FAILED", "Failed to resolve MySql connection").
+			WithCause(err)
+		if c.OnError != nil {
+			c.OnError(ctx, correlationId, err)
+		}
+		return err
 	}
 
 	c.Logger.Debug(ctx, correlationId, "Connecting to mysql")
 
+	pool, err := c.openPool(ctx, correlationId, uri)
+	if err != nil {
+		return err
+	}
+	c.poolLock.Lock()
+	c.uri = uri
+	c.Connection = pool
+	c.poolLock.Unlock()
+	return nil
+}
+
+// openPool opens a connection pool to the resolved uri with retries
+// and calls the OnOpen and OnError callbacks.
+// Opening is aborted with a CONTEXT_CANCELLED error as soon as the context is done.
+func (c *MySqlConnection) openPool(ctx context.Context, correlationId string, uri string) (*sql.DB, error) {
 	retries := c.retries
 	for retries > 0 {
+		if ctx.Err() != nil {
+			return nil, c.contextError(correlationId, ctx.Err())
+		}
 		pool, err := sql.Open("mysql", uri)
+		if err == nil {
+			idleTimeoutMS := c.Options.GetAsIntegerWithDefault("idle_timeout", DefaultIdleTimeout)
+			maxPoolSize := c.Options.GetAsIntegerWithDefault("max_pool_size", DefaultMaxPoolSize)
+			connectTimeoutMS := c.Options.GetAsIntegerWithDefault("connect_timeout", DefaultConnectTimeout)
+
+			pool.SetConnMaxIdleTime(time.Duration(idleTimeoutMS) * time.Millisecond)
+			pool.SetMaxOpenConns(maxPoolSize)
+			pool.SetConnMaxLifetime(time.Duration(connectTimeoutMS) * time.Millisecond)
+
+			// sql.Open doesn't connect, so check the server is reachable
+			err = c.ping(ctx, pool, maxPoolSize)
+			if err != nil {
+				pool.Close()
+			}
+		}
+		if err != nil && ctx.Err() != nil {
+			return nil, c.contextError(correlationId, ctx.Err())
+		}
 		if err != nil {
 			retries--
 			if retries <= 0 {
-				return cerr.
+				err = cerr.
 					NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").
 					WithCause(err)
+				if c.OnError != nil {
+					c.OnError(ctx, correlationId, err)
+				}
+				return nil, err
 			}
 			c.Logger.Debug(ctx, correlationId, "Failed to connect to mysqls, try reconnect...")
 			err = c.waitForRetry(ctx, correlationId, retries)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			continue
 		}
-		idleTimeoutMS := c.Options.GetAsIntegerWithDefault("idle_timeout", DefaultIdleTimeout)
-		maxPoolSize := c.Options.GetAsIntegerWithDefault("max_pool_size", DefaultMaxPoolSize)
-		connectTimeoutMS := c.Options.GetAsIntegerWithDefault("connect_timeout", DefaultConnectTimeout)
 
-		pool.SetConnMaxIdleTime(time.Duration(idleTimeoutMS) * time.Millisecond)
-		pool.SetMaxOpenConns(maxPoolSize)
-		pool.SetConnMaxLifetime(time.Duration(connectTimeoutMS) * time.Millisecond)
+		if c.OnOpen != nil {
+			if err = c.OnOpen(ctx, correlationId, pool); err != nil {
+				pool.Close()
+				if c.OnError != nil {
+					c.OnError(ctx, correlationId, err)
+				}
+				return nil, err
+			}
+		}
 
-		c.Connection = pool
-		break
+		return pool, nil
+	}
+	return nil, nil
+}
+
+// Reconnect replaces the connection pool with a new one opened with the connection string
+// resolved on opening, e.g. when a degraded connection is detected by other means.
+// The new pool is opened before the old one is closed, so the connection pool is never nil.
+// The old pool is closed after the new one is returned by GetConnection to all callers.
+// Operations started in the old pool are completed, new ones in it fail with the closed pool error.
+// When the new pool can't be opened the old one is kept.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occurred.
+func (c *MySqlConnection) Reconnect(ctx context.Context, correlationId string) error {
+	c.poolLock.RLock()
+	uri, opened := c.uri, c.Connection != nil
+	c.poolLock.RUnlock()
+	if !opened || uri == "" {
+		return c.Open(ctx, correlationId)
+	}
+
+	c.Logger.Debug(ctx, correlationId, "Reconnecting to mysql")
+
+	pool, err := c.openPool(ctx, correlationId, uri)
+	if err != nil {
+		return err
+	}
+	c.poolLock.Lock()
+	oldPool := c.Connection
+	c.Connection = pool
+	c.poolLock.Unlock()
+	if oldPool != nil {
+		oldPool.Close()
 	}
 	return nil
 }
@@ -157,17 +289,28 @@ func (c *MySqlConnection) Open(ctx context.Context, correlationId string) error
 //		- correlationId (optional) transaction id to trace execution through call chain.
 //	Returns: error or nil no errors occurred
 func (c *MySqlConnection) Close(ctx context.Context, correlationId string) error {
-	if c.Connection == nil {
+	c.poolLock.Lock()
+	pool := c.Connection
+	c.Connection = nil
+	c.uri = ""
+	c.poolLock.Unlock()
+	if pool == nil {
 		return nil
 	}
-	c.Connection.Close()
+	pool.Close()
 	c.Logger.Debug(ctx, correlationId, "Disconnected from mysql database %s", c.DatabaseName)
-	c.Connection = nil
 	c.DatabaseName = ""
+	if c.OnClose != nil {
+		c.OnClose(ctx, correlationId)
+	}
 	return nil
 }
 
+// GetConnection gets the current connection pool, which is replaced by Reconnect.
+//	Returns: the connection pool or nil when the connection is not opened.
 func (c *MySqlConnection) GetConnection() *sql.DB {
+	c.poolLock.RLock()
+	defer c.poolLock.RUnlock()
 	return c.Connection
 }
 
@@ -175,21 +318,113 @@ func (c *MySqlConnection) GetDatabaseName() string {
 	return c.DatabaseName
 }
 
+// GetCircuitBreaker gets the circuit breaker of the connection.
+//	Returns: the circuit breaker or nil when it is disabled.
+func (c *MySqlConnection) GetCircuitBreaker() *MySqlCircuitBreaker {
+	return c.circuitBreaker
+}
+
+// ping checks the server is reachable and warms up the pool.
+// The driver may not watch the context while dialing and in the handshake,
+// so waiting is aborted when the context is done and the check is stopped by closing the pool.
+func (c *MySqlConnection) ping(ctx context.Context, pool *sql.DB, maxPoolSize int) error {
+	done := make(chan error, 1)
+	go func() {
+		err := pool.PingContext(ctx)
+		if err == nil {
+			err = c.warmup(ctx, pool, maxPoolSize)
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// warmup opens the configured number of connections in the pool,
+// so the first requests don't wait for establishing them.
+func (c *MySqlConnection) warmup(ctx context.Context, pool *sql.DB, maxPoolSize int) error {
+	count := c.Options.GetAsIntegerWithDefault("warmup_connections", 0)
+	if maxPoolSize > 0 && count > maxPoolSize {
+		count = maxPoolSize
+	}
+	if count <= 0 {
+		return nil
+	}
+	// Keep the opened connections idle in the pool instead of closing them
+	if count > DefaultMaxIdleConns {
+		pool.SetMaxIdleConns(count)
+	}
+
+	// Connections are held until all of them are opened, otherwise the pool reuses the same one
+	conns := make([]*sql.Conn, 0, count)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for i := 0; i < count; i++ {
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if err = conn.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *MySqlConnection) waitForRetry(ctx context.Context, correlationId string, retries int) error {
-	waitTime := DefaultConnectTimeout * int(math.Pow(float64(c.retries-retries), 2))
+	waitTime := c.retryBackoff * int(math.Pow(float64(c.retries-retries), 2))
+	if waitTime > c.maxRetryBackoff {
+		waitTime = c.maxRetryBackoff
+	}
 
 	select {
 	case <-time.After(time.Duration(waitTime) * time.Millisecond):
 		return nil
 	case <-ctx.Done():
-		return cerr.ApplicationErrorFactory.Create(
-			&cerr.ErrorDescription{
-				Type:          "Application",
-				Category:      "Application",
-				Code:          "CONTEXT_CANCELLED",
-				Message:       "request canceled by parent context",
-				CorrelationId: correlationId,
-			},
-		)
+		return c.contextError(correlationId, ctx.Err())
+	}
+}
+
+// contextError creates an error of opening cancelled by the parent context.
+func (c *MySqlConnection) contextError(correlationId string, cause error) error {
+	return cerr.ApplicationErrorFactory.Create(
+		&cerr.ErrorDescription{
+			Type:          "Application",
+			Category:      "Application",
+			Code:          "CONTEXT_CANCELLED",
+			Message:       "request canceled by parent context",
+			CorrelationId: correlationId,
+		},
+	).WithCause(cause)
+}
+
+// TestConnection checks the configuration by opening a temporary connection,
+// pinging the server and closing the connection. It is useful for health checks
+// and setup wizards. Unless the configuration sets options.max_retries,
+// the connection is attempted only once to fail fast.
+//	Parameters:
+//		- ctx context.Context
+//		- config connection configuration parameters to check
+//	Returns: error or nil when the server is reachable with the configuration.
+func TestConnection(ctx context.Context, config *cconf.ConfigParams) error {
+	config = config.SetDefaults(cconf.NewConfigParamsFromTuples("options.max_retries", 1))
+
+	connection := NewMySqlConnection()
+	connection.Configure(ctx, config)
+
+	if err := connection.Open(ctx, ""); err != nil {
+		return err
 	}
+	defer connection.Close(ctx, "")
+
+	return connection.GetConnection().PingContext(ctx)
 }
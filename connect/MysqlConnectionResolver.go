@@ -3,7 +3,10 @@ package connect
 import (
 	"context"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
@@ -11,6 +14,7 @@ import (
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 	cauth "github.com/pip-services3-gox/pip-services3-components-gox/auth"
 	cconn "github.com/pip-services3-gox/pip-services3-components-gox/connect"
+	clog "github.com/pip-services3-gox/pip-services3-components-gox/log"
 )
 
 // Helper class that resolves MySQL connection and credential parameters,
@@ -24,12 +28,30 @@ import (
 //			- port:                        port number (default: 27017)
 //			- database:                    database name
 //			- uri:                         resource URI or connection string with all parameters in it
+//			- *:                           other parameters are added to the URI, when several connections
+//			                               define the same parameter the value from the first one is used
 //		- credential(s):
 //			- store_key:                   (optional) a key to retrieve the credentials from ICredentialStore
 //			- username:                    user name
 //			- password:                    user password
+//		- options:
+//			- interpolate_params:          (optional) interpolate query parameters in the driver to save
+//			                               a round trip to prepare statements on the server (default: false).
+//			                               Server-side prepared statements are not used then, and values are
+//			                               escaped by the driver, which is safe only with utf8/utf8mb4, latin1
+//			                               and other charsets without multibyte backslashes.
+//			- sql_mode:                    (optional) SQL mode set in sessions of all pooled connections,
+//			                               e.g. "STRICT_ALL_TABLES,NO_ZERO_DATE" (default: the server mode)
+//			- timezone:                    (optional) IANA time zone like "Europe/Berlin" or "UTC" to write time.Time values
+//			                               and read DATETIME values in, it sets the driver "loc" parameter,
+//			                               enables "parseTime" and sets the session time_zone, so NOW() and
+//			                               TIMESTAMP columns use the same zone. Named zones other than UTC require
+//			                               time zone tables loaded on the server (default: UTC)
+//			- application_name:            (optional) name of the application sent in the program_name connection attribute
+//			                               to identify connections in performance_schema.session_connect_attrs
 //
 //	References:
+//		- *:logger:*:*:1.0                (optional) ILogger components to pass log messages
 //		- *:discovery:*:*:1.0             (optional) IDiscovery services
 //		- *:credential-store:*:*:1.0      (optional) Credential stores to resolve credentials
 type MySqlConnectionResolver struct {
@@ -37,6 +59,15 @@ type MySqlConnectionResolver struct {
 	ConnectionResolver *cconn.ConnectionResolver
 	// The credentials' resolver.
 	CredentialResolver *cauth.CredentialResolver
+	// The logger.
+	Logger *clog.CompositeLogger
+
+	hasDiscovery      bool
+	interpolateParams bool
+	sqlMode           string
+	hasSqlMode        bool
+	timezone          string
+	applicationName   string
 }
 
 // NewMySqlConnectionResolver creates new connection resolver
@@ -45,6 +76,7 @@ func NewMySqlConnectionResolver() *MySqlConnectionResolver {
 	mongoCon := MySqlConnectionResolver{}
 	mongoCon.ConnectionResolver = cconn.NewEmptyConnectionResolver()
 	mongoCon.CredentialResolver = cauth.NewEmptyCredentialResolver()
+	mongoCon.Logger = clog.NewCompositeLogger()
 	return &mongoCon
 }
 
@@ -53,8 +85,46 @@ func NewMySqlConnectionResolver() *MySqlConnectionResolver {
 //		- ctx context.Context
 //		- config *cconf.ConfigParams configuration parameters to be set.
 func (c *MySqlConnectionResolver) Configure(ctx context.Context, config *cconf.ConfigParams) {
-	c.ConnectionResolver.Configure(ctx, config)
+	// Connections are added in the order of their indexes, so the first connection wins on conflicts
+	for _, connection := range newOrderedConnectionParams(config) {
+		c.ConnectionResolver.Add(connection)
+	}
 	c.CredentialResolver.Configure(ctx, config)
+	c.interpolateParams = config.GetAsBooleanWithDefault("options.interpolate_params", c.interpolateParams)
+	if sqlMode, ok := config.GetAsNullableString("options.sql_mode"); ok {
+		c.sqlMode, c.hasSqlMode = sqlMode, true
+	}
+	c.timezone = config.GetAsStringWithDefault("options.timezone", c.timezone)
+	c.applicationName = config.GetAsStringWithDefault("options.application_name", c.applicationName)
+}
+
+// newOrderedConnectionParams reads connections from the configuration in the order of their connections.N indexes,
+// as sections of the configuration are read from a map in a random order.
+func newOrderedConnectionParams(config *cconf.ConfigParams) []*cconn.ConnectionParams {
+	connections := config.GetSection("connections")
+	if connections.Len() == 0 {
+		return cconn.NewManyConnectionParamsFromConfig(config)
+	}
+
+	names := connections.GetSectionNames()
+	sort.Slice(names, func(i, j int) bool {
+		index1, err1 := strconv.Atoi(names[i])
+		index2, err2 := strconv.Atoi(names[j])
+		if err1 == nil && err2 == nil {
+			return index1 < index2
+		}
+		if err1 == nil || err2 == nil {
+			// Numeric indexes go before named sections
+			return err1 == nil
+		}
+		return names[i] < names[j]
+	})
+
+	result := make([]*cconn.ConnectionParams, 0, len(names))
+	for _, name := range names {
+		result = append(result, cconn.NewConnectionParams(connections.GetSection(name).Value()))
+	}
+	return result
 }
 
 // SetReferences is sets references to dependent components.
@@ -64,6 +134,8 @@ func (c *MySqlConnectionResolver) Configure(ctx context.Context, config *cconf.C
 func (c *MySqlConnectionResolver) SetReferences(ctx context.Context, references crefer.IReferences) {
 	c.ConnectionResolver.SetReferences(ctx, references)
 	c.CredentialResolver.SetReferences(ctx, references)
+	c.Logger.SetReferences(ctx, references)
+	c.hasDiscovery = len(references.GetOptional(crefer.NewDescriptor("*", "discovery", "*", "*", "*"))) > 0
 }
 
 func (c *MySqlConnectionResolver) validateConnection(correlationId string, connection *cconn.ConnectionParams) error {
@@ -89,6 +161,16 @@ func (c *MySqlConnectionResolver) validateConnection(correlationId string, conne
 
 func (c *MySqlConnectionResolver) validateConnections(correlationId string, connections []*cconn.ConnectionParams) error {
 	if len(connections) == 0 {
+		// Connections expected from discovery can't be resolved without discovery services
+		if !c.hasDiscovery {
+			for _, connection := range c.ConnectionResolver.GetAll() {
+				if connection.UseDiscovery() {
+					return cerr.NewConfigError(correlationId, "NO_DISCOVERY",
+						"Discovery service is not referenced to resolve connection with discovery_key "+connection.DiscoveryKey()).
+						WithDetails("discovery_key", connection.DiscoveryKey())
+				}
+			}
+		}
 		return cerr.NewConfigError(correlationId, "NO_CONNECTION", "Database connection is not set")
 	}
 	for _, connection := range connections {
@@ -101,14 +183,56 @@ func (c *MySqlConnectionResolver) validateConnections(correlationId string, conn
 	return nil
 }
 
-func (c *MySqlConnectionResolver) composeUri(connections []*cconn.ConnectionParams,
-	credential *cauth.CredentialParams) string {
+func (c *MySqlConnectionResolver) validateCredential(correlationId string, credential *cauth.CredentialParams) error {
+	if credential == nil {
+		return nil
+	}
+
+	// Reject placeholders like ${MYSQL_PASSWORD} left unexpanded in configuration
+	for _, key := range []string{"username", "password"} {
+		value, ok := credential.GetAsNullableString(key)
+		if ok && strings.Contains(value, "${") {
+			return cerr.NewConfigError(correlationId, "UNRESOLVED_CREDENTIAL",
+				"Credential "+key+" contains unresolved placeholder "+value).
+				WithDetails("credential", key)
+		}
+	}
+
+	if credential.Username() == "" && credential.Password() != "" {
+		return cerr.NewConfigError(correlationId, "NO_USERNAME", "Credential username is not set")
+	}
+	// The driver doesn't unescape credentials and ends the username at the first ':'
+	if strings.Contains(credential.Username(), ":") {
+		return cerr.NewConfigError(correlationId, "INVALID_CREDENTIAL",
+			"Credential username must not contain ':'").
+			WithDetails("credential", "username")
+	}
+	return nil
+}
+
+func (c *MySqlConnectionResolver) composeUri(ctx context.Context, correlationId string,
+	connections []*cconn.ConnectionParams, credential *cauth.CredentialParams) string {
 
 	// If there is an uri then return it immediately
 	for _, connection := range connections {
 		uri := connection.Uri()
 		if uri != "" {
-			return uri
+			if c.interpolateParams && !strings.Contains(uri, "interpolateParams=") {
+				uri = appendUriParam(uri, "interpolateParams=true")
+			}
+			if c.hasSqlMode && !strings.Contains(uri, "sql_mode=") {
+				uri = appendUriParam(uri, "sql_mode="+url.QueryEscape("'"+c.sqlMode+"'"))
+			}
+			if c.timezone != "" && !strings.Contains(uri, "loc=") {
+				uri = appendUriParam(uri, "loc="+url.QueryEscape(c.timezone))
+				if !strings.Contains(uri, "parseTime=") {
+					uri = appendUriParam(uri, "parseTime=true")
+				}
+			}
+			if timeZone := sessionTimeZone(c.timezone); timeZone != "" && !strings.Contains(uri, "time_zone=") {
+				uri = appendUriParam(uri, "time_zone="+url.QueryEscape("'"+timeZone+"'"))
+			}
+			return c.appendApplicationName(uri)
 		}
 	}
 
@@ -137,7 +261,9 @@ func (c *MySqlConnectionResolver) composeUri(connections []*cconn.ConnectionPara
 		database = "/" + database
 	}
 
-	// Define authentication part
+	// Define authentication part. The driver reads credentials verbatim up to the last '@'
+	// before the database and splits them at the first ':', so they are not escaped.
+	// Usernames with ':' are rejected in validateCredential, passwords may contain any characters
 	var auth = ""
 	if credential != nil {
 		var username = credential.Username()
@@ -150,10 +276,22 @@ func (c *MySqlConnectionResolver) composeUri(connections []*cconn.ConnectionPara
 			}
 		}
 	}
-	// Define additional parameters
+	// Define additional parameters, the first connection wins on conflicts
 	consConf := cdata.NewEmptyStringValueMap()
 	for _, v := range connections {
-		consConf.Append(v.Value())
+		for key, value := range v.Value() {
+			if key == "uri" || key == "host" || key == "port" || key == "database" {
+				continue
+			}
+			if existing, ok := consConf.Get(key); ok {
+				if existing != value {
+					c.Logger.Warn(ctx, correlationId,
+						"Connection parameter %s has conflicting values %s and %s, using %s", key, existing, value, existing)
+				}
+				continue
+			}
+			consConf.Put(key, value)
+		}
 	}
 	var options *cconf.ConfigParams
 	if credential != nil {
@@ -167,28 +305,64 @@ func (c *MySqlConnectionResolver) composeUri(connections []*cconn.ConnectionPara
 	options.Remove("database")
 	options.Remove("username")
 	options.Remove("password")
+	if c.interpolateParams && !options.Contains("interpolateParams") {
+		options.Put("interpolateParams", "true")
+	}
+	// The driver sets unknown parameters as session variables on each new connection
+	if c.hasSqlMode && !options.Contains("sql_mode") {
+		options.Put("sql_mode", "'"+c.sqlMode+"'")
+	}
+	if c.timezone != "" && !options.Contains("loc") {
+		options.Put("loc", c.timezone)
+		if !options.Contains("parseTime") {
+			options.Put("parseTime", "true")
+		}
+	}
+	if timeZone := sessionTimeZone(c.timezone); timeZone != "" && !options.Contains("time_zone") {
+		options.Put("time_zone", "'"+timeZone+"'")
+	}
+	// Keys and values are escaped separately to keep the '=' and '&' delimiters
 	params := ""
 	keys := options.Keys()
 	for _, key := range keys {
 		if len(params) > 0 {
 			params += "&"
 		}
-		params += key
+		params += url.QueryEscape(key)
 
 		value := options.GetAsString(key)
 		if value != "" {
-			params += "=" + value
+			params += "=" + url.QueryEscape(value)
 		}
 	}
 	if len(params) > 0 {
-		params = "?" + url.PathEscape(params)
+		params = "?" + params
 	}
 
 	// Compose uri
 
-	uri := url.PathEscape(auth) + "tcp(" + hosts + ")" + database + params
+	uri := auth + "tcp(" + hosts + ")" + database + params
 
-	return uri
+	return c.appendApplicationName(uri)
+}
+
+// appendApplicationName adds the application name to the URI as the program_name connection attribute
+// sent by the driver on connecting. Commas separate the attributes, so they are replaced in the name.
+// Connection attributes set in the URI are kept as is.
+func (c *MySqlConnectionResolver) appendApplicationName(uri string) string {
+	if c.applicationName == "" || strings.Contains(uri, "connectionAttributes=") {
+		return uri
+	}
+	name := strings.ReplaceAll(c.applicationName, ",", " ")
+	return appendUriParam(uri, "connectionAttributes="+url.QueryEscape("program_name:"+name))
+}
+
+// appendUriParam appends the parameter to the query of the URI.
+func appendUriParam(uri string, param string) string {
+	if strings.Contains(uri, "?") {
+		return uri + "&" + param
+	}
+	return uri + "?" + param
 }
 
 // Resolve method are resolves MySql connection URI from connection and credential parameters.
@@ -211,5 +385,30 @@ func (c *MySqlConnectionResolver) Resolve(ctx context.Context, correlationId str
 	if err != nil {
 		return "", err
 	}
-	return c.composeUri(connections, credential), nil
+	err = c.validateCredential(correlationId, credential)
+	if err != nil {
+		return "", err
+	}
+	if c.timezone != "" {
+		if _, err = time.LoadLocation(c.timezone); err != nil {
+			return "", cerr.NewConfigError(correlationId, "INVALID_TIMEZONE",
+				"Time zone "+c.timezone+" is not valid").
+				WithDetails("timezone", c.timezone).
+				WithCause(err)
+		}
+	}
+	return c.composeUri(ctx, correlationId, connections, credential), nil
+}
+
+// sessionTimeZone converts the configured time zone to a value of the time_zone session variable.
+// UTC is set as an offset, which doesn't require time zone tables on the server.
+// The local zone of the client is not known to the server, so the session keeps the server zone.
+func sessionTimeZone(timezone string) string {
+	switch timezone {
+	case "", "Local":
+		return ""
+	case "UTC":
+		return "+00:00"
+	}
+	return timezone
 }
@@ -0,0 +1,30 @@
+package persistence
+
+import "context"
+
+type correlationIdKey struct{}
+
+// ContextWithCorrelationId returns a copy of the context which carries the correlationId,
+// so loggers and other components called with the context can extract it.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: context with the correlationId or the same context when the correlationId is empty.
+func ContextWithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	if correlationId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIdKey{}, correlationId)
+}
+
+// GetCorrelationIdFromContext extracts the correlationId stored in the context by persistence operations.
+//	Parameters:
+//		- ctx context.Context
+//	Returns: the correlationId and true if it is stored in the context or false otherwise.
+func GetCorrelationIdFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	correlationId, ok := ctx.Value(correlationIdKey{}).(string)
+	return correlationId, ok
+}
@@ -3,9 +3,13 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"sort"
+	"strings"
 
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
 	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 )
 
 // IdentifiableJsonMySqlPersistence is an abstract persistence component that stores data in MySQL in JSON or JSONB fields
@@ -56,9 +60,8 @@ import (
 //	}
 //
 //	func (c *MyMySqlPersistence) DefineSchema() {
-//		c.ClearSchema()
 //		c.EnsureTable("", "")
-//		c.EnsureSchema("ALTER TABLE `" + c.TableName + "` ADD `data_key` VARCHAR(50) AS (JSON_UNQUOTE(`data`->\"$.key\"))")
+//		c.EnsureGeneratedColumn("data_key", "VARCHAR(50)", "JSON_UNQUOTE(`data`->\"$.key\")", false)
 //		c.EnsureIndex(c.TableName+"_json_key", map[string]string{"data_key": "1"}, map[string]string{"unique": "true"})
 //	}
 //
@@ -95,6 +98,8 @@ import (
 //
 type IdentifiableJsonMySqlPersistence[T any, K any] struct {
 	*IdentifiableMySqlPersistence[T, K]
+
+	jsonValidator func(data []byte) error
 }
 
 // InheritIdentifiableJsonMySqlPersistence creates a new instance of the persistence component.
@@ -107,6 +112,48 @@ func InheritIdentifiableJsonMySqlPersistence[T any, K any](overrides IMySqlPersi
 	return c
 }
 
+// WithSchema creates a view of the persistence bound to another schema.
+// The view shares the connection pool with the persistence.
+//	Parameters:
+//		- schema a schema name
+//	Returns: persistence view
+func (c *IdentifiableJsonMySqlPersistence[T, K]) WithSchema(schema string) *IdentifiableJsonMySqlPersistence[T, K] {
+	return c.WithTarget(schema, c.TableName)
+}
+
+// WithTable creates a view of the persistence bound to another table.
+// The view shares the connection pool with the persistence.
+//	Parameters:
+//		- table a table name
+//	Returns: persistence view
+func (c *IdentifiableJsonMySqlPersistence[T, K]) WithTable(table string) *IdentifiableJsonMySqlPersistence[T, K] {
+	return c.WithTarget(c.SchemaName, table)
+}
+
+// WithTarget creates a view of the persistence bound to another schema and table.
+// The view shares the connection pool with the persistence, see MySqlPersistence.WithTarget.
+//	Parameters:
+//		- schema (optional) a schema name
+//		- table a table name
+//	Returns: persistence view
+func (c *IdentifiableJsonMySqlPersistence[T, K]) WithTarget(schema string, table string) *IdentifiableJsonMySqlPersistence[T, K] {
+	return &IdentifiableJsonMySqlPersistence[T, K]{
+		IdentifiableMySqlPersistence: c.IdentifiableMySqlPersistence.WithTarget(schema, table),
+		jsonValidator:                c.jsonValidator,
+	}
+}
+
+// SetJsonValidator sets a function that validates JSON documents before they are persisted.
+// Documents rejected by the validator are not written. Create, Set and Update return
+// a BadRequest error with INVALID_JSON code, other writes return the error of the validator.
+// Keep in mind that UpdatePartially and UpdateJsonFields change the documents on the server
+// and are not validated.
+//	Parameters:
+//		- validator a function that returns an error for invalid documents, nil to disable the validation
+func (c *IdentifiableJsonMySqlPersistence[T, K]) SetJsonValidator(validator func(data []byte) error) {
+	c.jsonValidator = validator
+}
+
 // EnsureTable Adds DML statement to automatically create JSON(B) table
 //	Parameters:
 //   - idType type of the id column (default: VARCHAR(32))
@@ -123,7 +170,8 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) EnsureTable(idType string, data
 		query := "CREATE SCHEMA IF NOT EXISTS " + c.QuoteIdentifier(c.SchemaName)
 		c.EnsureSchema(query)
 	}
-	query := "CREATE TABLE IF NOT EXISTS " + c.QuotedTableName() + " (`id` " + idType + " PRIMARY KEY, `data` " + dataType + ")"
+	query := "CREATE TABLE IF NOT EXISTS " + c.quotedBoundTableName() + " (" + c.QuoteIdentifier("id") + " " + idType +
+		" PRIMARY KEY, " + c.QuoteIdentifier("data") + " " + dataType + ")"
 	c.EnsureSchema(query)
 }
 
@@ -182,6 +230,11 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) ConvertFromPublic(value T) (map
 	if convErr != nil {
 		return nil, convErr
 	}
+	if c.jsonValidator != nil {
+		if err := c.jsonValidator([]byte(data)); err != nil {
+			return nil, &jsonValidationError{id: id, err: err}
+		}
+	}
 
 	result := map[string]any{
 		"id":   id,
@@ -190,6 +243,55 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) ConvertFromPublic(value T) (map
 	return result, nil
 }
 
+// Create creates a data item.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be created.
+// Returns: created item or error.
+func (c *IdentifiableJsonMySqlPersistence[T, K]) Create(ctx context.Context, correlationId string, item T) (T, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	result, err := c.IdentifiableMySqlPersistence.Create(ctx, correlationId, item)
+	return result, wrapJsonValidationError(correlationId, err)
+}
+
+// Set sets a data item. If the data item exists it updates it,
+// otherwise it create a new data item.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be set.
+// Returns: updated item or error.
+func (c *IdentifiableJsonMySqlPersistence[T, K]) Set(ctx context.Context, correlationId string, item T) (T, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	result, err := c.IdentifiableMySqlPersistence.Set(ctx, correlationId, item)
+	return result, wrapJsonValidationError(correlationId, err)
+}
+
+// Update updates a data item.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be updated.
+// Returns: updated item or error.
+func (c *IdentifiableJsonMySqlPersistence[T, K]) Update(ctx context.Context, correlationId string, item T) (T, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	result, err := c.IdentifiableMySqlPersistence.Update(ctx, correlationId, item)
+	return result, wrapJsonValidationError(correlationId, err)
+}
+
+// UpdateWithResult updates a data item and tells whether an item with its id was found.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be updated.
+// Returns: updated item, true when the item was found or error.
+func (c *IdentifiableJsonMySqlPersistence[T, K]) UpdateWithResult(ctx context.Context, correlationId string, item T) (T, bool, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	result, updated, err := c.IdentifiableMySqlPersistence.UpdateWithResult(ctx, correlationId, item)
+	return result, updated, wrapJsonValidationError(correlationId, err)
+}
+
 // ConvertFromPublicPartial convert object value from public to internal format.
 //	Parameters:
 //		- value     an object in public format to convert.
@@ -197,10 +299,13 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) ConvertFromPublic(value T) (map
 func (c *IdentifiableJsonMySqlPersistence[T, K]) ConvertFromPublicPartial(value map[string]any) (map[string]any, error) {
 	buf, toJsonErr := cconv.JsonConverter.ToJson(value)
 	if toJsonErr != nil {
+		if err := checkSerializable("", "", value); err != nil {
+			return nil, err
+		}
 		return nil, toJsonErr
 	}
 	item, fromJsonErr := c.IdentifiableMySqlPersistence.JsonConvertor.FromJson(buf)
-	if toJsonErr != nil {
+	if fromJsonErr != nil {
 		return nil, fromJsonErr
 	}
 	return c.ConvertFromPublic(item)
@@ -215,22 +320,42 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) ConvertFromPublicPartial(value
 // Returns: receives updated item or error.
 func (c *IdentifiableJsonMySqlPersistence[T, K]) UpdatePartially(ctx context.Context, correlationId string,
 	id K, data cdata.AnyValueMap) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	data, err = c.filterUpdatableFields(ctx, correlationId, data)
+	if err != nil {
+		return result, err
+	}
 	buf, toJsonErr := cconv.JsonConverter.ToJson(data.Value())
 	if toJsonErr != nil {
+		if err := checkSerializable(correlationId, "", data.Value()); err != nil {
+			return result, err
+		}
 		return result, toJsonErr
 	}
 
-	query := "UPDATE " + c.QuotedTableName() + " SET `data`=JSON_MERGE_PATCH(data,?) WHERE id=?"
+	column := c.QuoteIdentifier("data")
+	query := "UPDATE " + c.QuotedTableName() + " SET " + column + "=JSON_MERGE_PATCH(" + column + ",?) WHERE id=?"
 	values := []any{buf, id}
 
-	_, err = c.IdentifiableMySqlPersistence.Client.ExecContext(ctx, query, values...)
+	_, err = c.exec(ctx, correlationId, "update_partially", query, values...)
+	c.removeCached(ctx, correlationId, id)
 	if err != nil {
 		return result, err
 	}
+	if !c.returnOnWrite {
+		// Only the id and updated fields are set in the returned item
+		c.Logger.Trace(ctx, correlationId, "Updated partially in %s with id = %s", c.TableName, id)
+		return c.partialItem(id, data)
+	}
 
 	// Getting result
 	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
-	rows, err := c.Client.QueryContext(ctx, query, []any{id}...)
+	rows, err := c.query(ctx, correlationId, "update_partially", query, []any{id}...)
 	if err != nil {
 		return result, err
 	}
@@ -238,16 +363,169 @@ func (c *IdentifiableJsonMySqlPersistence[T, K]) UpdatePartially(ctx context.Con
 	defer rows.Close()
 
 	if !rows.Next() {
-		return result, rows.Err()
+		return result, c.wrapError(ctx, correlationId, "update_partially", rows.Err())
 	}
 
 	if err == nil {
-		result, convErr := c.IdentifiableMySqlPersistence.Overrides.ConvertToPublic(rows)
+		result, convErr := c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return result, convErr
+			return result, c.wrapError(ctx, correlationId, "update_partially", convErr)
 		}
 		c.IdentifiableMySqlPersistence.Logger.Trace(ctx, correlationId, "Updated partially in %s with id = %s", c.IdentifiableMySqlPersistence.TableName, id)
 		return result, nil
 	}
-	return result, rows.Err()
+	return result, c.wrapError(ctx, correlationId, "update_partially", rows.Err())
+}
+
+// UpdateJsonFields updates only specified fields inside the JSON data of a data item.
+// Unlike UpdatePartially the fields are set on the server with JSON_SET,
+// so nested objects are not replaced and concurrent updates of other fields are kept.
+// With options.version_column the version is incremented like in Update and a field named
+// as the version column is checked as the expected version instead of being set.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- id                an id of data item to be updated.
+//		- fields            a map of values keyed by JSON paths like "$.name" or "address.city",
+//		                    the "$." prefix is added when it is missing.
+// Returns: receives updated item or error.
+func (c *IdentifiableJsonMySqlPersistence[T, K]) UpdateJsonFields(ctx context.Context, correlationId string,
+	id K, fields map[string]any) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	if len(fields) == 0 {
+		return c.GetOneById(ctx, correlationId, id)
+	}
+
+	// Paths are sorted to generate the same statement for the same fields
+	paths := make([]string, 0, len(fields))
+	var version any
+	for path, value := range fields {
+		if c.versionColumn != "" && strings.EqualFold(strings.TrimPrefix(path, "$."), c.versionColumn) {
+			version = value
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	setParams := ""
+	values := make([]any, 0, len(paths)*2+2)
+	for _, path := range paths {
+		// Values are passed as JSON to keep their types
+		buf, toJsonErr := cconv.JsonConverter.ToJson(fields[path])
+		if toJsonErr != nil {
+			if err := checkSerializable(correlationId, path, fields[path]); err != nil {
+				return result, err
+			}
+			return result, toJsonErr
+		}
+		jsonPath := path
+		if !strings.HasPrefix(jsonPath, "$") {
+			jsonPath = "$." + jsonPath
+		}
+		setParams += ",?,CAST(? AS JSON)"
+		values = append(values, jsonPath, buf)
+	}
+	values = append(values, id)
+
+	column := c.QuoteIdentifier("data")
+	params := ""
+	if len(paths) > 0 {
+		params = column + "=JSON_SET(" + column + setParams + ")"
+	}
+	condition := "id=?"
+	if version != nil {
+		condition += " AND " + c.QuoteIdentifier(c.versionColumn) + "=?"
+		values = append(values, version)
+	}
+	query := "UPDATE " + c.QuotedTableName() + " SET " + c.versionSetParameters(params) + " WHERE " + condition
+
+	res, err := c.exec(ctx, correlationId, "update_json_fields", query, values...)
+	c.removeCached(ctx, correlationId, id)
+	if err == nil && version != nil {
+		err = c.checkVersionUpdated(correlationId, res, id, version)
+	}
+	if err != nil {
+		return result, err
+	}
+	if !c.returnOnWrite && c.versionColumn == "" {
+		// Only the id and updated fields are set in the returned item
+		c.Logger.Trace(ctx, correlationId, "Updated fields %s in %s with id = %s", strings.Join(paths, ","), c.TableName, id)
+		return c.partialItem(id, *cdata.NewAnyValueMap(jsonFieldsMap(paths, fields)))
+	}
+
+	// Getting result
+	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
+	rows, err := c.query(ctx, correlationId, "update_json_fields", query, id)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return result, rows.Err()
+	}
+
+	result, err = c.convertToPublic(correlationId, rows)
+	if err != nil {
+		return result, err
+	}
+	c.Logger.Trace(ctx, correlationId, "Updated fields %s in %s with id = %s", strings.Join(paths, ","), c.TableName, id)
+	return result, nil
+}
+
+// jsonFieldsMap converts values keyed by JSON paths into nested maps of the updated fields.
+// Paths with array indexes or quoted keys can't be converted and are skipped.
+func jsonFieldsMap(paths []string, fields map[string]any) map[string]any {
+	result := make(map[string]any)
+	for _, path := range paths {
+		name := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if name == "" || strings.ContainsAny(name, "[]\"*") {
+			continue
+		}
+		keys := strings.Split(name, ".")
+		parent := result
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				parent[key] = child
+			}
+			parent = child
+		}
+		parent[keys[len(keys)-1]] = fields[path]
+	}
+	return result
+}
+
+// jsonValidationError is returned by ConvertFromPublic for documents rejected by the JSON validator.
+// It unwraps to the error of the validator.
+type jsonValidationError struct {
+	id  any
+	err error
+}
+
+func (e *jsonValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *jsonValidationError) Unwrap() error {
+	return e.err
+}
+
+// wrapJsonValidationError converts an error of the JSON validator into a BadRequest error.
+func wrapJsonValidationError(correlationId string, err error) error {
+	var validationErr *jsonValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	return cerr.NewBadRequestError(correlationId, "INVALID_JSON",
+		"Data item is rejected by the JSON validator: "+validationErr.err.Error()).
+		WithDetails("id", validationErr.id).
+		WithCause(validationErr.err)
 }
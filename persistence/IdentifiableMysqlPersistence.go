@@ -2,6 +2,10 @@ package persistence
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
 
 	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	cpersist "github.com/pip-services3-gox/pip-services3-data-gox/persistence"
@@ -53,7 +57,6 @@ import (
 //	}
 //
 //	func (c *MyMySqlPersistence) DefineSchema() {
-//		c.ClearSchema()
 //		c.IdentifiableMySqlPersistence.DefineSchema()
 //		// Row name must be in double quotes for properly case!!!
 //		c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT)")
@@ -110,9 +113,85 @@ func InheritIdentifiableMySqlPersistence[T any, K any](overrides IMySqlPersisten
 	c := &IdentifiableMySqlPersistence[T, K]{}
 	c.MySqlPersistence = InheritMySqlPersistence[T](overrides, tableName)
 
+	switch reflect.TypeOf((*K)(nil)).Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		c.numericId = true
+	}
+
 	return c
 }
 
+// WithSchema creates a view of the persistence bound to another schema.
+// The view shares the connection pool with the persistence.
+//	Parameters:
+//		- schema a schema name
+//	Returns: persistence view
+func (c *IdentifiableMySqlPersistence[T, K]) WithSchema(schema string) *IdentifiableMySqlPersistence[T, K] {
+	return c.WithTarget(schema, c.TableName)
+}
+
+// WithTable creates a view of the persistence bound to another table.
+// The view shares the connection pool with the persistence.
+//	Parameters:
+//		- table a table name
+//	Returns: persistence view
+func (c *IdentifiableMySqlPersistence[T, K]) WithTable(table string) *IdentifiableMySqlPersistence[T, K] {
+	return c.WithTarget(c.SchemaName, table)
+}
+
+// WithTarget creates a view of the persistence bound to another schema and table.
+// The view shares the connection pool with the persistence, see MySqlPersistence.WithTarget.
+//	Parameters:
+//		- schema (optional) a schema name
+//		- table a table name
+//	Returns: persistence view
+func (c *IdentifiableMySqlPersistence[T, K]) WithTarget(schema string, table string) *IdentifiableMySqlPersistence[T, K] {
+	return &IdentifiableMySqlPersistence[T, K]{
+		MySqlPersistence: c.MySqlPersistence.WithTarget(schema, table),
+	}
+}
+
+// GetPageByFilterWithIndex gets a page of data items retrieved by a given filter
+// together with a map of the retrieved items by their ids.
+// It is a function and not a method because map keys require comparable id type.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- persistence       a persistence to retrieve the items from
+//		- filter            (optional) a filter JSON object
+//		- paging            (optional) paging parameters
+//		- sort              (optional) sorting JSON object
+//		- select            (optional) projection JSON object
+//		- args              (optional) values of parameters used in the filter
+//	Returns: data page, items by ids or error.
+func GetPageByFilterWithIndex[T any, K comparable](ctx context.Context, correlationId string,
+	persistence *IdentifiableMySqlPersistence[T, K], filter string, paging cdata.PagingParams,
+	sort string, selection string, args ...any) (page cdata.DataPage[T], index map[K]T, err error) {
+
+	page, err = persistence.GetPageByFilter(ctx, correlationId, filter, paging, sort, selection, args...)
+	if err != nil {
+		return page, nil, err
+	}
+
+	index = make(map[K]T, len(page.Data))
+	for _, item := range page.Data {
+		index[GetObjectId[K](item)] = item
+	}
+	return page, index, nil
+}
+
+// bindIds converts ids to values of query parameters. Ids of integer id columns given as
+// strings, JSON numbers or float64, e.g. ids of map items, are bound as exact integers like written values,
+// since MySQL compares integers with strings as floating point numbers.
+func (c *IdentifiableMySqlPersistence[T, K]) bindIds(ids []K) []any {
+	values := ItemsToAnySlice(ids)
+	for i, id := range values {
+		values[i] = c.coerceIntegerValue("id", id)
+	}
+	return values
+}
+
 // GetListByIds gets a list of data items retrieved by given unique ids.
 //	Parameters:
 //		- ctx context.Context
@@ -121,12 +200,22 @@ func InheritIdentifiableMySqlPersistence[T any, K any](overrides IMySqlPersisten
 //	Returns: a data list or error.
 func (c *IdentifiableMySqlPersistence[T, K]) GetListByIds(ctx context.Context, correlationId string,
 	ids []K) (items []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
 
+	// IN() with no values is invalid SQL, so nothing is queried
 	ln := len(ids)
+	if ln == 0 {
+		return make([]T, 0), nil
+	}
 	params := c.GenerateParameters(ln)
 	query := "SELECT * FROM " + c.QuotedTableName() + " WHERE id IN(" + params + ")"
 
-	rows, err := c.Client.QueryContext(ctx, query, ItemsToAnySlice(ids)...)
+	rows, err := c.query(ctx, correlationId, "get_list_by_ids", query, c.bindIds(ids)...)
 	if err != nil {
 		return nil, err
 	}
@@ -140,9 +229,9 @@ func (c *IdentifiableMySqlPersistence[T, K]) GetListByIds(ctx context.Context, c
 				NewError("query terminated").
 				WithCorrelationId(correlationId)
 		}
-		item, convErr := c.Overrides.ConvertToPublic(rows)
+		item, convErr := c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return items, convErr
+			return items, c.wrapError(ctx, correlationId, "get_list_by_ids", convErr)
 		}
 		items = append(items, item)
 	}
@@ -151,7 +240,7 @@ func (c *IdentifiableMySqlPersistence[T, K]) GetListByIds(ctx context.Context, c
 		c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
 	}
 
-	return items, rows.Err()
+	return items, c.wrapError(ctx, correlationId, "get_list_by_ids", rows.Err())
 }
 
 // GetOneById gets a data item by its unique id.
@@ -161,22 +250,38 @@ func (c *IdentifiableMySqlPersistence[T, K]) GetListByIds(ctx context.Context, c
 //		- id                an id of data item to be retrieved.
 // Returns: data item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) GetOneById(ctx context.Context, correlationId string, id K) (item T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return item, err
+	}
+
+	if cached, ok := c.retrieveCached(ctx, correlationId, id); ok {
+		c.Logger.Trace(ctx, correlationId, "Retrieved from cache of %s with id = %s", c.TableName, id)
+		return cached, nil
+	}
 
 	query := "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
 
-	rows, err := c.Client.QueryContext(ctx, query, id)
+	rows, err := c.query(ctx, correlationId, "get_one_by_id", query, id)
 	if err != nil {
-		return item, err
+		return item, c.wrapQueryError(ctx, correlationId, "get_one_by_id", err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
-		return item, rows.Err()
+		return item, c.wrapError(ctx, correlationId, "get_one_by_id", rows.Err())
 	}
 
 	if err == nil {
 		c.Logger.Trace(ctx, correlationId, "Retrieved from %s with id = %s", c.TableName, id)
-		return c.Overrides.ConvertToPublic(rows)
+		item, err = c.convertToPublic(correlationId, rows)
+		if err != nil {
+			return item, c.wrapError(ctx, correlationId, "get_one_by_id", err)
+		}
+		c.storeCached(ctx, correlationId, id, item)
+		return item, nil
 	}
 	c.Logger.Trace(ctx, correlationId, "Nothing found from %s with id = %s", c.TableName, id)
 	return item, err
@@ -189,20 +294,94 @@ func (c *IdentifiableMySqlPersistence[T, K]) GetOneById(ctx context.Context, cor
 //		- item              an item to be created.
 //	Returns: (optional)  created item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) Create(ctx context.Context, correlationId string, item T) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if c.autoIncrementId {
+		return c.createWithAutoIncrement(ctx, correlationId, item)
+	}
 	newItem := c.cloneItem(item)
 	newItem = GenerateObjectIdIfNotExists[T](newItem)
 
 	return c.MySqlPersistence.Create(ctx, correlationId, newItem)
 }
 
+// createWithAutoIncrement inserts the item without an empty id
+// and returns the stored item with the id generated by the server.
+func (c *IdentifiableMySqlPersistence[T, K]) createWithAutoIncrement(ctx context.Context, correlationId string, item T) (result T, err error) {
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	objMap, convErr := c.Overrides.ConvertFromPublic(item)
+	if convErr != nil {
+		return result, convErr
+	}
+	if id, ok := objMap["id"]; ok && (id == nil || reflect.ValueOf(id).IsZero()) {
+		delete(objMap, "id")
+	}
+
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, err
+	}
+	columns, values := c.GenerateColumnsAndValues(objMap)
+
+	columnsStr := c.GenerateColumns(columns)
+	paramsStr := c.GenerateParameters(len(values))
+
+	query := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+	c.traceParams(ctx, correlationId, columns, values)
+
+	res, err := c.exec(ctx, correlationId, "create", query, values...)
+	if err != nil {
+		return result, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return result, err
+	}
+
+	// Getting result
+	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
+	rows, err := c.query(ctx, correlationId, "create", query, id)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return result, rows.Err()
+	}
+
+	result, err = c.convertToPublic(correlationId, rows)
+	if err != nil {
+		return result, err
+	}
+	c.Logger.Trace(ctx, correlationId, "Created in %s with id = %d", c.TableName, id)
+	return result, nil
+}
+
 // Set a data item. If the data item exists it updates it,
-// otherwise it creates a new data item.
+// otherwise it creates a new data item. With options.strict_insert
+// existing items are not updated and a conflict error is returned.
 //	Parameters:
 //		- ctx context.Context
 //		- correlation_id    (optional) transaction id to trace execution through call chain.
 //		- item              an item to be set.
 //	Returns: (optional)  updated item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) Set(ctx context.Context, correlationId string, item T) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	if !c.returnOnWrite {
+		// The id is generated in the returned item
+		item = GenerateObjectIdIfNotExists[T](c.cloneItem(item))
+	}
 	objMap, convErr := c.Overrides.ConvertFromPublic(item)
 	if convErr != nil {
 		return result, convErr
@@ -210,45 +389,266 @@ func (c *IdentifiableMySqlPersistence[T, K]) Set(ctx context.Context, correlatio
 
 	GenerateObjectMapIdIfNotExists(objMap)
 
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, err
+	}
 	columns, values := c.GenerateColumnsAndValues(objMap)
 
 	paramsStr := c.GenerateParameters(len(values))
 	columnsStr := c.GenerateColumns(columns)
 	setParams := c.GenerateSetParameters(columns)
 	id := cpersist.GetObjectId(objMap)
-
-	values = append(values, values...)
-
-	query := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
-	query += " ON DUPLICATE KEY UPDATE " + setParams
-
-	_, err = c.Client.ExecContext(ctx, query, values...)
+	c.traceParams(ctx, correlationId, columns, values)
+
+	if c.strictInsert {
+		query := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+		_, err = c.exec(ctx, correlationId, "set", query, values...)
+		err = c.wrapDuplicateError(correlationId, id, err)
+	} else if c.isUniqueColumn("id") {
+		// Updated columns refer to the inserted values, so parameters are bound only once
+		query := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+		query += " ON DUPLICATE KEY UPDATE " + c.GenerateUpsertParameters(columns)
+
+		_, err = c.exec(ctx, correlationId, "set", query, values...)
+	} else {
+		// Without unique key upsert always inserts, so check existing row explicitly
+		err = c.setWithoutUniqueKey(ctx, correlationId, id, columnsStr, paramsStr, setParams, values)
+	}
+	c.removeCached(ctx, correlationId, id)
 	if err != nil {
 		return result, err
 	}
+	if !c.returnOnWrite {
+		c.Logger.Trace(ctx, correlationId, "Set in %s with id = %s", c.TableName, id)
+		return item, nil
+	}
 
 	// Getting result
-	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
-	rows, err := c.Client.QueryContext(ctx, query, []any{id}...)
+	query := "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
+	rows, err := c.query(ctx, correlationId, "set", query, []any{id}...)
 	if err != nil {
 		return result, err
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
-		return result, rows.Err()
+		return result, c.wrapError(ctx, correlationId, "set", rows.Err())
 	}
 
 	if err == nil {
-		result, convErr = c.Overrides.ConvertToPublic(rows)
+		result, convErr = c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return result, convErr
+			return result, c.wrapError(ctx, correlationId, "set", convErr)
 		}
 		c.Logger.Trace(ctx, correlationId, "Set in %s with id = %s", c.TableName, id)
 		return result, nil
 	}
-	return result, rows.Err()
+	return result, c.wrapError(ctx, correlationId, "set", rows.Err())
+
+}
+
+// setWithoutUniqueKey updates the item when it exists and inserts it otherwise in a transaction.
+// The transaction is run like other writes: through the circuit breaker and with retries
+// in the reconnected pool or after deadlocks.
+func (c *IdentifiableMySqlPersistence[T, K]) setWithoutUniqueKey(ctx context.Context, correlationId string, id any,
+	columnsStr string, paramsStr string, setParams string, values []any) error {
+
+	query := "SELECT COUNT(*) FROM " + c.QuotedTableName() + " WHERE id=? FOR UPDATE"
+	updateQuery := "UPDATE " + c.QuotedTableName() + " SET " + setParams + " WHERE id=?"
+	updateValues := append(append(make([]any, 0, len(values)+1), values...), id)
+	insertQuery := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+
+	_, err := c.execWith(ctx, correlationId, "set", query, []any{id}, func() (sql.Result, error) {
+		// The pool is read on each run to use the one replaced by reconnecting
+		client, _ := c.getClient()
+		if client == nil {
+			return nil, c.notOpenedError(correlationId)
+		}
+		tx, err := client.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		var count int64
+		if err = tx.QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+			return nil, err
+		}
+
+		var result sql.Result
+		if count > 0 {
+			c.logQuery(ctx, correlationId, updateQuery, updateValues)
+			result, err = tx.ExecContext(ctx, updateQuery, updateValues...)
+		} else {
+			c.logQuery(ctx, correlationId, insertQuery, values)
+			result, err = tx.ExecContext(ctx, insertQuery, values...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, tx.Commit()
+	})
+	return err
+}
+
+// CreateBatch creates data items with multi-row INSERT statements, see MySqlPersistence.CreateBatch.
+// Ids are generated for items without them, including map items without the "id" key, and preset ids are kept.
+// With options.auto_increment_id items are created one by one to return the ids generated by the server.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- items             items to be created.
+//	Returns: created items or error.
+func (c *IdentifiableMySqlPersistence[T, K]) CreateBatch(ctx context.Context, correlationId string, items []T) (result []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if c.autoIncrementId {
+		result = make([]T, 0, len(items))
+		for _, item := range items {
+			created, err := c.createWithAutoIncrement(ctx, correlationId, item)
+			if err != nil {
+				return result, err
+			}
+			result = append(result, created)
+		}
+		return result, nil
+	}
+
+	newItems := make([]T, len(items))
+	for i, item := range items {
+		newItems[i] = c.generateItemId(c.cloneItem(item))
+	}
+	return c.MySqlPersistence.CreateBatch(ctx, correlationId, newItems)
+}
+
+// generateItemId generates an id for an item without it and keeps preset ids.
+// Unlike GenerateObjectMapIdIfNotExists, ids of map items are also generated when the "id" key
+// is missing or nil, but only for string ids.
+func (c *IdentifiableMySqlPersistence[T, K]) generateItemId(item T) T {
+	mapItem, ok := any(item).(map[string]any)
+	if !ok {
+		return GenerateObjectIdIfNotExists[T](item)
+	}
+	var key K
+	if _, ok := any(key).(string); !ok {
+		return item
+	}
+	if id, ok := mapItem["id"]; !ok || id == nil || id == "" {
+		mapItem["id"] = cdata.IdGenerator.NextLong()
+	}
+	return item
+}
+
+// SetBatch sets data items with multi-row upserts split like in MySqlPersistence.CreateBatch.
+// Ids are generated for items without them and preset ids are kept. Stored items are not read back, the given items are returned.
+// With options.strict_insert or without a unique key on ids items are set one by one like in Set.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- items             items to be set.
+//	Returns: set items or error.
+func (c *IdentifiableMySqlPersistence[T, K]) SetBatch(ctx context.Context, correlationId string, items []T) (result []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if c.strictInsert || !c.isUniqueColumn("id") {
+		result = make([]T, 0, len(items))
+		for _, item := range items {
+			setItem, err := c.Set(ctx, correlationId, item)
+			if err != nil {
+				return result, err
+			}
+			result = append(result, setItem)
+		}
+		return result, nil
+	}
+
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
+	newItems := make([]T, len(items))
+	for i, item := range items {
+		newItems[i] = c.generateItemId(c.cloneItem(item))
+	}
+	objMaps, err := c.convertBatch(ctx, correlationId, newItems)
+	if err != nil {
+		return nil, err
+	}
 
+	err = c.insertBatch(ctx, correlationId, "set_batch", objMaps, true)
+	for _, objMap := range objMaps {
+		c.removeCached(ctx, correlationId, cpersist.GetObjectId(objMap))
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Logger.Trace(ctx, correlationId, "Set %d in %s", len(newItems), c.TableName)
+	return newItems, nil
+}
+
+// Replace a data item using REPLACE INTO. Unlike Set, which updates
+// an existing row in place, the existing row is deleted and a new one is inserted,
+// so DELETE triggers are fired and columns missing in the item are reset to their defaults.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be replaced.
+//	Returns: (optional)  stored item or error.
+func (c *IdentifiableMySqlPersistence[T, K]) Replace(ctx context.Context, correlationId string, item T) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	objMap, convErr := c.Overrides.ConvertFromPublic(item)
+	if convErr != nil {
+		return result, convErr
+	}
+
+	GenerateObjectMapIdIfNotExists(objMap)
+
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, err
+	}
+	columns, values := c.GenerateColumnsAndValues(objMap)
+
+	paramsStr := c.GenerateParameters(len(values))
+	columnsStr := c.GenerateColumns(columns)
+	id := cpersist.GetObjectId(objMap)
+
+	query := "REPLACE INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+	c.traceParams(ctx, correlationId, columns, values)
+
+	_, err = c.exec(ctx, correlationId, "replace", query, values...)
+	c.removeCached(ctx, correlationId, id)
+	if err != nil {
+		return result, err
+	}
+
+	// Getting result
+	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
+	rows, err := c.query(ctx, correlationId, "replace", query, id)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return result, rows.Err()
+	}
+
+	result, err = c.convertToPublic(correlationId, rows)
+	if err != nil {
+		return result, err
+	}
+	c.Logger.Trace(ctx, correlationId, "Replaced in %s with id = %s", c.TableName, id)
+	return result, nil
 }
 
 // Update a data item.
@@ -258,43 +658,111 @@ func (c *IdentifiableMySqlPersistence[T, K]) Set(ctx context.Context, correlatio
 //		- item              an item to be updated.
 //	Returns          (optional)  updated item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) Update(ctx context.Context, correlationId string, item T) (result T, err error) {
+	result, _, err = c.UpdateWithResult(ctx, correlationId, item)
+	return result, err
+}
+
+// UpdateWithResult updates a data item like Update and tells whether an item with its id was found,
+// so an update of a missing item is distinguished from a successful one.
+// Items updated with the same values are counted as updated.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- item              an item to be updated.
+//	Returns: updated item, true when the item was found or error.
+func (c *IdentifiableMySqlPersistence[T, K]) UpdateWithResult(ctx context.Context, correlationId string,
+	item T) (result T, updated bool, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, false, err
+	}
 	objMap, convErr := c.Overrides.ConvertFromPublic(item)
 	if convErr != nil {
-		return result, convErr
+		return result, false, convErr
+	}
+	version, hasVersion := c.takeVersion(objMap)
+
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, false, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, false, err
 	}
 	columns, values := c.GenerateColumnsAndValues(objMap)
-	paramsStr := c.GenerateSetParameters(columns)
+	paramsStr := c.versionSetParameters(c.GenerateSetParameters(columns))
 	id := cpersist.GetObjectId(objMap)
 	values = append(values, id)
+	condition := "id=?"
+	if hasVersion {
+		condition += " AND " + c.QuoteIdentifier(c.versionColumn) + "=?"
+		values = append(values, version)
+	}
 
-	query := "UPDATE " + c.QuotedTableName() + " SET " + paramsStr + " WHERE id=?"
+	query := "UPDATE " + c.QuotedTableName() + " SET " + paramsStr + " WHERE " + condition
+	c.traceParams(ctx, correlationId, columns, values)
 
-	_, err = c.Client.ExecContext(ctx, query, values...)
+	res, err := c.exec(ctx, correlationId, "update", query, values...)
+	c.removeCached(ctx, correlationId, id)
+	if err == nil && hasVersion {
+		err = c.checkVersionUpdated(correlationId, res, id, version)
+	}
 	if err != nil {
-		return result, err
+		return result, false, err
+	}
+	// The incremented version is read back, so the item can be updated again
+	if !c.returnOnWrite && c.versionColumn == "" {
+		updated, err = c.checkUpdated(ctx, correlationId, res, id)
+		if err != nil || !updated {
+			return result, false, err
+		}
+		c.Logger.Trace(ctx, correlationId, "Updated in %s with id = %s", c.TableName, id)
+		return item, true, nil
 	}
 
 	// Getting result
 	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
-	rows, err := c.Client.QueryContext(ctx, query, []any{id}...)
+	rows, err := c.query(ctx, correlationId, "update", query, []any{id}...)
 	if err != nil {
-		return result, err
+		return result, false, err
 	}
 
 	defer rows.Close()
 	if !rows.Next() {
-		return result, rows.Err()
+		return result, false, c.wrapError(ctx, correlationId, "update", rows.Err())
 	}
 
 	if err == nil {
-		result, convErr = c.Overrides.ConvertToPublic(rows)
+		result, convErr = c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return result, convErr
+			return result, false, c.wrapError(ctx, correlationId, "update", convErr)
 		}
 		c.Logger.Trace(ctx, correlationId, "Updated in %s with id = %s", c.TableName, id)
-		return result, nil
+		return result, true, nil
 	}
-	return result, err
+	return result, false, err
+}
+
+// checkUpdated tells whether an update found the item with the given id.
+// The server counts only changed rows, so the item is looked up when no rows were changed.
+func (c *IdentifiableMySqlPersistence[T, K]) checkUpdated(ctx context.Context, correlationId string,
+	res sql.Result, id any) (bool, error) {
+	count, err := res.RowsAffected()
+	if err == nil && count > 0 {
+		return true, nil
+	}
+
+	query := "SELECT 1 FROM " + c.QuotedTableName() + " WHERE id=?"
+	rows, err := c.query(ctx, correlationId, "update", query, id)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return true, nil
+	}
+	return false, c.wrapError(ctx, correlationId, "update", rows.Err())
 }
 
 // UpdatePartially updates only few selected fields in a data item.
@@ -305,41 +773,132 @@ func (c *IdentifiableMySqlPersistence[T, K]) Update(ctx context.Context, correla
 //		- data              a map with fields to be updated.
 //	Returns: updated item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) UpdatePartially(ctx context.Context, correlationId string, id K, data cdata.AnyValueMap) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
+	data, err = c.filterUpdatableFields(ctx, correlationId, data)
+	if err != nil {
+		return result, err
+	}
 	objMap, convErr := c.Overrides.ConvertFromPublicPartial(data.Value())
+	if appErr, ok := convErr.(*cerr.ApplicationError); ok && appErr.CorrelationId == "" {
+		appErr.CorrelationId = correlationId
+	}
 	if convErr != nil {
 		return result, convErr
 	}
+	version, hasVersion := c.takeVersion(objMap)
+
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, err
+	}
 	columns, values := c.GenerateColumnsAndValues(objMap)
-	paramsStr := c.GenerateSetParameters(columns)
+	paramsStr := c.versionSetParameters(c.GenerateSetParameters(columns))
 	values = append(values, id)
+	condition := "id=?"
+	if hasVersion {
+		condition += " AND " + c.QuoteIdentifier(c.versionColumn) + "=?"
+		values = append(values, version)
+	}
 
-	query := "UPDATE " + c.QuotedTableName() + " SET " + paramsStr + " WHERE id=?"
+	query := "UPDATE " + c.QuotedTableName() + " SET " + paramsStr + " WHERE " + condition
+	c.traceParams(ctx, correlationId, columns, values)
 
-	_, err = c.Client.ExecContext(ctx, query, values...)
+	res, err := c.exec(ctx, correlationId, "update_partially", query, values...)
+	c.removeCached(ctx, correlationId, id)
+	if err == nil && hasVersion {
+		err = c.checkVersionUpdated(correlationId, res, id, version)
+	}
 	if err != nil {
 		return result, err
 	}
+	if !c.returnOnWrite && c.versionColumn == "" {
+		// Only the id and updated fields are set in the returned item
+		c.Logger.Trace(ctx, correlationId, "Updated partially in %s with id = %s", c.TableName, id)
+		return c.partialItem(id, data)
+	}
 
 	query = "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
-	rows, err := c.Client.QueryContext(ctx, query, []any{id}...)
+	rows, err := c.query(ctx, correlationId, "update_partially", query, []any{id}...)
 	if err != nil {
 		return result, err
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
-		return result, rows.Err()
+		return result, c.wrapError(ctx, correlationId, "update_partially", rows.Err())
 	}
 
 	if err == nil {
-		result, convErr = c.Overrides.ConvertToPublic(rows)
+		result, convErr = c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return result, convErr
+			return result, c.wrapError(ctx, correlationId, "update_partially", convErr)
 		}
 		c.Logger.Trace(ctx, correlationId, "Updated partially in %s with id = %s", c.TableName, id)
 		return result, nil
 	}
-	return result, rows.Err()
+	return result, c.wrapError(ctx, correlationId, "update_partially", rows.Err())
+}
+
+// takeVersion removes the version from the updated values and returns it as the expected version.
+// Updates without the version increment it without checking.
+func (c *IdentifiableMySqlPersistence[T, K]) takeVersion(objMap map[string]any) (version any, ok bool) {
+	if c.versionColumn == "" {
+		return nil, false
+	}
+	for column, value := range objMap {
+		if strings.EqualFold(column, c.versionColumn) {
+			delete(objMap, column)
+			return value, value != nil
+		}
+	}
+	return nil, false
+}
+
+// versionSetParameters adds the version increment to the update parameters.
+func (c *IdentifiableMySqlPersistence[T, K]) versionSetParameters(setParams string) string {
+	if c.versionColumn == "" {
+		return setParams
+	}
+	if setParams != "" {
+		setParams += ","
+	}
+	column := c.QuoteIdentifier(c.versionColumn)
+	return setParams + column + "=" + column + "+1"
+}
+
+// checkVersionUpdated returns a conflict error when the update with the expected version didn't change any rows,
+// i.e. the item was changed by another update or deleted.
+func (c *IdentifiableMySqlPersistence[T, K]) checkVersionUpdated(correlationId string, res sql.Result, id any, version any) error {
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return cerr.NewConflictError(correlationId, "VERSION_CONFLICT",
+		"Item with id "+fmt.Sprint(id)+" in "+c.TableName+" was changed by another update or deleted").
+		WithDetails("id", id).
+		WithDetails("version", version)
+}
+
+// partialItem converts the updated fields to a data item with the given id.
+func (c *IdentifiableMySqlPersistence[T, K]) partialItem(id K, data cdata.AnyValueMap) (T, error) {
+	fields := CloneMapValue(data.Value()).(map[string]any)
+	fields["id"] = id
+	buf, err := c.JsonMapConvertor.ToJson(fields)
+	if err != nil {
+		var defaultValue T
+		return defaultValue, err
+	}
+	return c.JsonConvertor.FromJson(buf)
 }
 
 // DeleteById deletes a data item by its unique id.
@@ -349,33 +908,45 @@ func (c *IdentifiableMySqlPersistence[T, K]) UpdatePartially(ctx context.Context
 //		- id                an id of the item to be deleted
 //	Returns: (optional)  deleted item or error.
 func (c *IdentifiableMySqlPersistence[T, K]) DeleteById(ctx context.Context, correlationId string, id K) (result T, err error) {
-	query := "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
-
-	rows, err := c.Client.QueryContext(ctx, query, []any{id}...)
-	if err != nil {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
 		return result, err
 	}
+	query := "SELECT * FROM " + c.QuotedTableName() + " WHERE id=?"
 
-	query = "DELETE FROM " + c.QuotedTableName() + " WHERE id=?"
-	_, err = c.Client.ExecContext(ctx, query, []any{id}...)
+	rows, err := c.query(ctx, correlationId, "delete_by_id", query, []any{id}...)
 	if err != nil {
 		return result, err
 	}
 	defer rows.Close()
 
-	if !rows.Next() {
-		return result, rows.Err()
+	found := rows.Next()
+	if found {
+		result, err = c.convertToPublic(correlationId, rows)
+		if err != nil {
+			return result, c.wrapError(ctx, correlationId, "delete_by_id", err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return result, c.wrapError(ctx, correlationId, "delete_by_id", err)
 	}
+	// The rows hold a pooled connection, so they are closed before the delete takes another one
+	rows.Close()
 
-	if err == nil {
-		result, convErr := c.Overrides.ConvertToPublic(rows)
-		if convErr != nil {
-			return result, convErr
-		}
+	query = "DELETE FROM " + c.QuotedTableName() + " WHERE id=?"
+	_, err = c.execDelete(ctx, correlationId, "delete_by_id", query, []any{id}...)
+	c.removeCached(ctx, correlationId, id)
+	if err != nil {
+		var defaultValue T
+		return defaultValue, err
+	}
+
+	if found {
 		c.Logger.Trace(ctx, correlationId, "Deleted from %s with id = %s", c.TableName, id)
-		return result, nil
 	}
-	return result, rows.Err()
+	return result, nil
 }
 
 // DeleteByIds deletes multiple data items by their unique ids.
@@ -385,13 +956,25 @@ func (c *IdentifiableMySqlPersistence[T, K]) DeleteById(ctx context.Context, cor
 //		- ids                of data items to be deleted.
 //	Returns: (optional)  error or null for success.
 func (c *IdentifiableMySqlPersistence[T, K]) DeleteByIds(ctx context.Context, correlationId string, ids []K) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
 
 	ln := len(ids)
+	if ln == 0 {
+		return nil
+	}
 	paramsStr := c.GenerateParameters(ln)
 
 	query := "DELETE FROM " + c.QuotedTableName() + " WHERE id IN(" + paramsStr + ")"
 
-	result, err := c.Client.ExecContext(ctx, query, ItemsToAnySlice(ids)...)
+	result, err := c.execDelete(ctx, correlationId, "delete_by_ids", query, c.bindIds(ids)...)
+	for _, id := range ids {
+		c.removeCached(ctx, correlationId, id)
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// DefaultMigrationsTableName is a name of the table that tracks applied migrations
+const DefaultMigrationsTableName = "migrations"
+
+// Timeout of waiting for migrations applied by another process in seconds
+const migrationsLockTimeout = 60
+
+// Migration is a versioned change of the database objects applied by RunMigrations.
+// Keep in mind that MySQL commits DDL statements like ALTER TABLE implicitly,
+// so only data changes of a failed migration are rolled back.
+type Migration struct {
+	// Version of the migration, migrations are applied in ascending order of versions
+	Version int64
+	// Description saved with the applied version
+	Description string
+	// Statements executed in the migration transaction
+	Statements []string
+}
+
+// RunMigrations applies migrations of the table which versions are not applied yet.
+// Applied versions are saved per table in the migrations table of the same schema,
+// which is created when it doesn't exist. Each migration runs in its own transaction
+// and concurrent runs from other processes wait until the migrations are applied.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- migrations       migrations to apply
+//	Returns: error or nil when all migrations are applied.
+func (c *MySqlPersistence[T]) RunMigrations(ctx context.Context, correlationId string, migrations []Migration) (err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
+
+	pending := make([]Migration, len(migrations))
+	copy(pending, migrations)
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	for i := 1; i < len(pending); i++ {
+		if pending[i].Version == pending[i-1].Version {
+			return cerr.NewConfigError(correlationId, "DUPLICATE_MIGRATION",
+				"Migration version "+strconv.FormatInt(pending[i].Version, 10)+" is defined more than once").
+				WithDetails("version", pending[i].Version)
+		}
+	}
+
+	migrationsTable := c.QuotedTableNameFor(c.SchemaName, c.migrationsTable)
+	tableName, version := c.QuoteIdentifier("table_name"), c.QuoteIdentifier("version")
+	query := "CREATE TABLE IF NOT EXISTS " + migrationsTable + " (" + tableName + " VARCHAR(64) NOT NULL, " +
+		version + " BIGINT NOT NULL, " + c.QuoteIdentifier("description") + " VARCHAR(255), " +
+		c.QuoteIdentifier("applied_at") + " DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+		"PRIMARY KEY (" + tableName + ", " + version + "))"
+	if _, err = c.exec(ctx, correlationId, "run_migrations", query); err != nil {
+		return err
+	}
+
+	// The named lock belongs to the session, so migrations run on a dedicated connection
+	client, _ := c.getClient()
+	if client == nil {
+		return c.notOpenedError(correlationId)
+	}
+	dbConn, err := client.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	lockName := "migrations:" + c.SchemaName + "." + c.TableName
+	if len(lockName) > 64 {
+		lockName = lockName[:64]
+	}
+	var locked sql.NullInt64
+	if err = dbConn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, migrationsLockTimeout).Scan(&locked); err != nil {
+		return err
+	}
+	if locked.Int64 != 1 {
+		return cerr.NewConflictError(correlationId, "MIGRATIONS_LOCKED",
+			"Migrations of "+c.TableName+" are applied by another process").
+			WithDetails("table", c.TableName)
+	}
+	defer dbConn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	// Applied versions are read under the lock to skip migrations applied concurrently
+	applied := make(map[int64]bool)
+	rows, err := dbConn.QueryContext(ctx, "SELECT "+version+" FROM "+migrationsTable+" WHERE "+tableName+"=?", c.TableName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		if err = rows.Scan(&version); err != nil {
+			return err
+		}
+		applied[version] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, migration := range pending {
+		if applied[migration.Version] {
+			continue
+		}
+		if err = c.applyMigration(ctx, correlationId, dbConn, migrationsTable, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration executes statements of the migration and saves its version in one transaction.
+func (c *MySqlPersistence[T]) applyMigration(ctx context.Context, correlationId string,
+	dbConn *sql.Conn, migrationsTable string, migration Migration) (err error) {
+
+	version := strconv.FormatInt(migration.Version, 10)
+	defer func() {
+		if err != nil {
+			err = cerr.NewInternalError(correlationId, "MIGRATION_FAILED",
+				"Failed to apply migration "+version+" to "+c.TableName).
+				WithDetails("version", migration.Version).
+				WithDetails("table", c.TableName).
+				WithCause(err)
+		}
+	}()
+
+	tx, err := dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range migration.Statements {
+		c.logQuery(ctx, correlationId, statement, nil)
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	query := "INSERT INTO " + migrationsTable + " (" + c.GenerateColumns([]string{"table_name", "version", "description"}) +
+		") VALUES (?, ?, ?)"
+	if _, err = tx.ExecContext(ctx, query, c.TableName, migration.Version, migration.Description); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	c.Logger.Debug(ctx, correlationId, "Applied migration %s to %s: %s", version, c.TableName, migration.Description)
+	return nil
+}
@@ -3,23 +3,111 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"math/rand"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
 	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
 	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	cref "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	ccache "github.com/pip-services3-gox/pip-services3-components-gox/cache"
+	ccount "github.com/pip-services3-gox/pip-services3-components-gox/count"
 	clog "github.com/pip-services3-gox/pip-services3-components-gox/log"
 	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
 )
 
+// RedactedValue replaces sensitive parameter values in logs
+const RedactedValue = "***"
+
+// TimeoutErrorCode is a code of errors returned when an operation exceeds the context deadline
+const TimeoutErrorCode = "TIMEOUT"
+
+// CircuitOpenErrorCode is a code of errors returned without calling the database while the circuit breaker is open
+const CircuitOpenErrorCode = "CIRCUIT_OPEN"
+
+// Encodings of binary column values in the JSON representation of data items
+const (
+	BinaryEncodingNone   = "none"
+	BinaryEncodingBase64 = "base64"
+	BinaryEncodingHex    = "hex"
+)
+
+// Handling of written columns which are not found in the table
+const (
+	UnknownColumnsKeep  = "keep"
+	UnknownColumnsDrop  = "drop"
+	UnknownColumnsError = "error"
+)
+
+// Styles of quoting identifiers in generated queries
+const (
+	IdentifierQuoteBacktick = "backtick"
+	IdentifierQuoteAnsi     = "ansi"
+)
+
+// MySQL error numbers handled by the persistence
+const (
+	errNoSuchTable     = 1146
+	errDuplicateEntry  = 1062
+	errLockWaitTimeout = 1205
+	errLockDeadlock    = 1213
+)
+
+var collationSanitizer = regexp.MustCompile("[^A-Za-z0-9_]")
+
+// Escapes LIKE metacharacters with "!", which doesn't depend on the NO_BACKSLASH_ESCAPES mode
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Operations which only read data and can be routed to the read replica
+var readOperations = map[string]bool{
+	"get_page":            true,
+	"get_count":           true,
+	"get_list":            true,
+	"get_list_by_ids":     true,
+	"get_one_by_id":       true,
+	"get_page_by_cursor":  true,
+	"get_one_random":      true,
+	"get_first":           true,
+	"get_distinct_values": true,
+	"get_all":             true,
+	"count_all":           true,
+}
+
+// Operations that change data items and are retried on deadlocks and lock wait timeouts
+var writeOperations = map[string]bool{
+	"create":             true,
+	"set":                true,
+	"replace":            true,
+	"update":             true,
+	"update_partially":   true,
+	"update_json_fields": true,
+	"delete_by_id":       true,
+	"delete_by_ids":      true,
+	"delete_by_filter":   true,
+	"create_batch":       true,
+	"set_batch":          true,
+}
+
+// Maximum number of placeholders in a prepared statement
+const maxBatchParameters = 65535
+
 type IMySqlPersistenceOverrides[T any] interface {
 	DefineSchema()
 	ConvertFromPublic(item T) (map[string]any, error)
@@ -27,6 +115,13 @@ type IMySqlPersistenceOverrides[T any] interface {
 	ConvertFromPublicPartial(item map[string]any) (map[string]any, error)
 }
 
+// IRowScanner is an optional interface of child classes that scan columns
+// of a row directly into fields of the data item. When it is implemented
+// the persistence uses it instead of ConvertToPublic to avoid the JSON round-trip.
+type IRowScanner[T any] interface {
+	ScanRow(rows *sql.Rows) (T, error)
+}
+
 // MySqlPersistence Abstract persistence component that stores data in MySql using plain driver.
 //
 // This is the most basic persistence component that is only
@@ -36,7 +131,8 @@ type IMySqlPersistenceOverrides[T any] interface {
 //
 //	Configuration parameters
 //		- collection:                  (optional) MySql collection name
-//		- schema:                  	   (optional) MySql schema, default "public"
+//		- schema:                      (optional) MySql schema (database) to qualify the table name, by default the table is not qualified
+//		- raw_table:                   (optional) trusted SQL used verbatim in queries instead of the quoted table name, e.g. a view or a derived table
 //		- connection(s):
 //			- discovery_key:             (optional) a key to retrieve the connection from IDiscovery
 //			- host:                      host name or IP address
@@ -46,15 +142,82 @@ type IMySqlPersistenceOverrides[T any] interface {
 //			- store_key:                 (optional) a key to retrieve the credentials from ICredentialStore
 //			- username:                  (optional) user name
 //			- password:                  (optional) user password
+//		- read_connection:             (optional) connection to the read replica, reading queries are executed in the primary connection when it is not set
+//			- host:                      host name or IP address
+//			- port:                      port number
+//			- database:                  database name
+//			- uri:                       resource URI or connection string with all parameters in it
+//		- read_credential:             (optional) credentials of the read replica, the primary ones are used when not set
+//			- username:                  (optional) user name
+//			- password:                  (optional) user password
 //		- options:
-//			- connect_timeout:      (optional) number of milliseconds to wait before timing out when connecting a new client (default: 0)
+//			- connect_timeout:      (optional) number of milliseconds to wait before timing out when connecting a new client (default: 5000)
 //			- idle_timeout:         (optional) number of milliseconds a client must sit idle in the pool and not be checked out (default: 10000)
-//			- max_pool_size:        (optional) maximum number of clients the pool should contain (default: 10)
+//			- max_pool_size:        (optional) maximum number of clients the pool should contain (default: 2)
+//			- max_page_size:        (optional) maximum number of items returned in a page (default: 100)
+//			- default_page_size:    (optional) number of items returned in a page when paging take is zero or negative, 0 to use max_page_size (default: 0)
+//			- null_as_empty:        (optional) read NULL values as empty strings, otherwise as JSON null (default: true)
+//			- nested_separator:     (optional) separator in column aliases to read them into nested objects, e.g. with "__" the column
+//			                        "address__city" is read into the city field of the address field (default: no nesting)
+//			- strict_columns:       (optional) return an error when read columns are not mapped to fields of the data type (default: false)
+//			- split_set_columns:    (optional) read values of SET columns as arrays of their members and write arrays to SET columns
+//			                        as comma-separated values, otherwise the values are read and written as strings (default: false)
+//			- unknown_columns:      (optional) handling of written columns which are not found in the table, "keep" to write them as is,
+//			                        "drop" to skip them or "error" to fail with an UNKNOWN_COLUMNS error listing them (default: "keep")
+//			- log_params:           (optional) log parameters bound to write statements at trace level (default: false)
+//			- redact_columns:       (optional) comma-separated list of columns which values are masked in logged parameters
+//			- redact_positions:     (optional) comma-separated list of zero-based parameter positions which values are masked in logged parameters
+//			- updatable_columns:    (optional) comma-separated list of columns which UpdatePartially is allowed to update (default: all columns)
+//			- protected_columns:    (optional) comma-separated list of columns which UpdatePartially is not allowed to update, e.g. "id,created_at"
+//			- drop_protected_columns: (optional) silently drop fields of partial updates which are not allowed to update,
+//			                        otherwise UpdatePartially fails with a PROTECTED_COLUMN error (default: false)
+//			- strict_insert:        (optional) make Set insert new items only and return a conflict error for existing ones (default: false)
+//			- disable_foreign_keys_on_delete: (optional) delete items in transactions with disabled foreign key checks, so parents can be deleted before their children (default: false)
+//			- auto_increment_id:    (optional) let the server generate ids in integer AUTO_INCREMENT columns and return created items with them (default: false)
+//			- preserve_column_order: (optional) order columns in generated statements by declaration of the data type fields, otherwise the order is random (default: true)
+//			- operation_timeout_ms: (optional) number of milliseconds to wait for an operation to complete, 0 to wait with no limit (default: 0)
+//			- schema_max_retries:   (optional) number of attempts to create database objects on transient lock errors (default: 3)
+//			- schema_retry_backoff_ms: (optional) number of milliseconds to wait before the next attempt, multiplied by the attempt number (default: 1000)
+//			- write_max_retries:    (optional) number of retries of writes aborted by a deadlock or a lock wait timeout, 0 to disable retries (default: 2)
+//			- write_retry_backoff_ms: (optional) number of milliseconds to wait before the next retry of a write, multiplied by the retry number (default: 100)
+//			- recreate_schema:      (optional) recreate missing database objects when the table is not found, e.g. after reconnecting to a restored server (default: false)
+//			- cast_filter_values:   (optional) cast values of generated filters to column types from the table metadata (default: true)
+//			- log_queries:          (optional) log executed queries with types of bound parameters at debug level, values are never logged (default: false)
+//			- slow_query_threshold_ms: (optional) number of milliseconds of a query to log a warning with its operation, table and duration, 0 to disable (default: 0)
+//			- circuit_breaker_threshold: (optional) number of consecutive connection failures to fail fast, 0 to disable the breaker (default: 0)
+//			- circuit_breaker_cooldown_ms: (optional) number of milliseconds to fail fast before the next attempt (default: 30000)
+//			- max_prepared_statements: (optional) maximum number of cached prepared statements, 0 to disable the cache (default: 0)
+//			- qualify_schema:       (optional) qualify the table name with the connection database when the schema is not set (default: false)
+//			- interpolate_params:   (optional) interpolate query parameters in the driver instead of preparing statements on the server, see MySqlConnectionResolver (default: false)
+//			- sql_mode:             (optional) SQL mode set in sessions of all pooled connections, see MySqlConnectionResolver (default: the server mode)
+//			- timezone:             (optional) time zone to write time.Time values and read DATETIME values in, see MySqlConnectionResolver (default: UTC)
+//			- application_name:     (optional) name of the application to identify connections on the server, see MySqlConnectionResolver
+//			- migrations_table:     (optional) name of the table to track migrations applied by RunMigrations (default: "migrations")
+//			- approximate_count:    (optional) make CountAll return the fast row estimate from the table statistics instead of counting rows (default: false)
+//			- binary_encoding:      (optional) encoding of binary column values, "base64" or "hex", fields of []byte type require "base64", "none" reads and writes raw strings (default: "none")
+//			- return_on_write:      (optional) read stored items back after Set, Update, UpdatePartially and UpdateJsonFields, otherwise the given values are returned without extra queries.
+//			                        Updates still read items back when version_column is set to return the incremented version (default: true)
+//			- identifier_quote:     (optional) style of quoting identifiers in generated queries, "backtick" or "ansi" for double quotes
+//			                        required when the ANSI_QUOTES sql mode is enabled (default: "backtick")
+//			- version_column:       (optional) name of the column with item versions for optimistic locking, Update, UpdatePartially and UpdateJsonFields increment the version
+//			                        and fail with a conflict when the given version is not the stored one (default: no version checks)
+//			- get_all_warn_size:    (optional) number of rows read by GetAll to log a warning about a large result, 0 to disable the warning (default: 10000)
+//			- max_packet_size:      (optional) maximum size in bytes of statements generated by CreateBatch and SetBatch,
+//			                        the max_allowed_packet of the server is read on opening when it is not set (default: 0)
+//			- auto_create_schema:   (optional) create database objects defined by DefineSchema on opening when the table is missing,
+//			                        otherwise opening fails with a TABLE_NOT_FOUND error when the table is missing (default: true)
+//			- cache_timeout:        (optional) number of milliseconds to keep items read by GetOneById in the referenced cache (default: 60000)
+//			- debug:                (optional) track cursors opened by queries and log a warning for each of them left open on Close (default: false)
 //
 //	References:
 //		- *:logger:*:*:1.0           (optional) ILogger components to pass log messages
+//		- *:counters:*:*:1.0         (optional) ICounters components to pass collected measurements
 //		- *:discovery:*:*:1.0        (optional) IDiscovery services
 //		- *:credential-store:*:*:1.0 (optional) Credential stores to resolve credentials
+//		- *:cache:*:*:1.0            (optional) ICache component to keep items read by GetOneById.
+//		                              Items are removed from the cache when they are written or deleted,
+//		                              all items of the table cached by the persistence are removed by
+//		                              Clear, Truncate, DeleteByFilter and DeleteByFilterBatched
 //
 // Example:
 //
@@ -171,22 +334,137 @@ type MySqlPersistence[T any] struct {
 	opened           bool
 	localConnection  bool
 	schemaStatements []string
+	// Index names keyed by the statements that create them
+	schemaIndexes map[string]string
+	// Views share the connection and the schema of the persistence they are created from
+	view bool
+	// The persistence the view is created from, nil for persistence components
+	source *MySqlPersistence[T]
+	// Columns covered by single-column unique keys, nil if unknown
+	uniqueColumns map[string]bool
+	nullAsEmpty   bool
+	// Reject columns which are not mapped to fields of T
+	strictColumns bool
+	// Positions of fields of T by lowercase JSON names, nil when T accepts any field
+	publicFields map[string]int
+	// Kinds of fields of T by lowercase JSON names, nil when T accepts any field
+	publicKinds map[string]reflect.Kind
+	// Orders generated columns by positions of fields of T
+	preserveColumnOrder bool
+	// Recreates database objects when the table is missing
+	recreateSchema bool
+	// Set inserts new items only and fails on existing ones
+	strictInsert bool
+	// Create takes ids generated by AUTO_INCREMENT columns
+	autoIncrementId bool
+	// Ids are read as JSON numbers for integer id types
+	numericId bool
+	// Deletes run in transactions with disabled foreign key checks
+	disableForeignKeys bool
+	// Timeout of operations in milliseconds, 0 when not limited
+	operationTimeout int
+	// Retries of schema creation on transient errors
+	schemaRetries      int
+	schemaRetryBackoff int
+	// Retries of writes aborted by deadlocks and lock wait timeouts
+	writeRetries      int
+	writeRetryBackoff int
+	// Column data types by lowercase column names, nil until they are read from the table
+	columnTypes      map[string]string
+	columnTypesLock  sync.RWMutex
+	columnTypesLoad  sync.Mutex
+	castFilterValues bool
+	// Handling of written columns which are not found in the table
+	unknownColumns string
+	// Values of SET columns are read and written as arrays
+	splitSetColumns bool
+	// Parameters logging and redaction rules
+	logParams       bool
+	logQueries      bool
+	redactColumns   map[string]bool
+	redactPositions map[int]bool
+	// Columns allowed and not allowed to update partially, nil when not limited
+	updatableColumns     map[string]bool
+	protectedColumns     map[string]bool
+	dropProtectedColumns bool
+	// Prepared statements cache, nil when disabled
+	maxStatements int
+	statements    *StatementCache
+	// Guards the pools and the statements cache replaced on opening, closing and reconnecting
+	clientLock sync.RWMutex
+	// Queries run in the current pool of the connection, otherwise in the Client set by SetClient
+	useConnectionPool bool
+	// Operations are measured only when counters are referenced
+	hasCounters bool
+	// Duration of queries in milliseconds to log them as slow, 0 when disabled
+	slowQueryThreshold int
+	// Number of rows read by GetAll to warn about, 0 when disabled
+	getAllWarnSize int
+	// Sets the schema to the connection database when it is not set
+	qualifySchema bool
+	// Table that tracks applied migrations
+	migrationsTable string
+	// CountAll reads the row estimate from the table statistics
+	approximateCount bool
+	// Encoding of binary column values
+	binaryEncoding string
+	// Writes read stored items back
+	returnOnWrite bool
+	// Separator of nested fields in column aliases, empty when disabled
+	nestedSeparator string
+	// Column of item versions checked and incremented by updates, empty when disabled
+	versionColumn string
+	// Style of quoting identifiers, backticks or ANSI double quotes
+	identifierQuote string
+	// Creates missing database objects on opening, otherwise only checks the table exists
+	autoCreateSchema bool
+	// Cache of items read by ids, nil when not referenced
+	cache        ccache.ICache
+	cacheTimeout int64
+	// Keys of items stored in the cache, to remove them when many items are deleted
+	cachedKeys     map[string]bool
+	cachedKeysLock sync.Mutex
+	// Size limit of batch statements in bytes, 0 when unknown
+	maxPacketSize int
+	// Tracks opened cursors to report the ones left open on Close
+	debug bool
+	// Queries of tracked cursors which may still be open
+	openRows     map[*sql.Rows]string
+	openRowsLock sync.Mutex
 
 	//The dependency resolver.
 	DependencyResolver *cref.DependencyResolver
 	//The logger.
 	Logger *clog.CompositeLogger
+	//The performance counters.
+	Counters *ccount.CompositeCounters
 	//The MySql connection component.
 	Connection *conn.MySqlConnection
-	//The MySql connection pool object.
+	//The MySql connection pool object. After opening queries run in the current pool of the connection,
+	//which may be replaced by reconnecting.
 	Client *sql.DB
+	//The MySql connection component of the read replica, nil when no replica is configured.
+	ReadConnection *conn.MySqlConnection
+	//The MySql connection pool object of the read replica, nil when no replica is configured.
+	//Reads run in the current pool of ReadConnection.
+	ReadClient *sql.DB
 	//The MySql database name.
 	DatabaseName string
-	//The MySql database schema name. If not set use "public" by default
+	//The MySql database schema name. In MySQL a schema is a database,
+	//if not set the table name is not qualified and the connection database is used
 	SchemaName string
 	//The MySql table object.
-	TableName   string
+	TableName string
+	//Trusted SQL used verbatim in queries instead of the quoted table name, e.g. a view or a derived table.
+	//Database objects are still created and checked by TableName.
+	RawTableName string
+	//The maximum number of items returned in a page, larger requested pages are clamped to it.
 	MaxPageSize int
+	//The number of items returned in a page when paging doesn't set it, 0 to use MaxPageSize.
+	DefaultPageSize int
+	// Generates a correlationId for operations called with an empty one.
+	// If not set the empty correlationId is passed as is.
+	CorrelationIdGenerator func(ctx context.Context) string
 
 	// Defines channel which closed before closing persistence and signals about terminating
 	// all going processes
@@ -208,17 +486,37 @@ func InheritMySqlPersistence[T any](overrides IMySqlPersistenceOverrides[T], tab
 			"options.max_pool_size", 2,
 			"options.keep_alive", 1,
 			"options.connect_timeout", 5000,
+			"options.idle_timeout", 10000,
 			"options.auto_reconnect", true,
 			"options.max_page_size", 100,
-			"options.debug", true,
+			"options.debug", false,
 		),
-		schemaStatements: make([]string, 0),
-		Logger:           clog.NewCompositeLogger(),
-		MaxPageSize:      100,
-		TableName:        tableName,
-		JsonConvertor:    cconv.NewDefaultCustomTypeJsonConvertor[T](),
-		JsonMapConvertor: cconv.NewDefaultCustomTypeJsonConvertor[map[string]any](),
-		isTerminated:     make(chan struct{}),
+		schemaStatements:    make([]string, 0),
+		schemaIndexes:       make(map[string]string),
+		Logger:              clog.NewCompositeLogger(),
+		Counters:            ccount.NewCompositeCounters(),
+		MaxPageSize:         100,
+		TableName:           tableName,
+		JsonConvertor:       cconv.NewDefaultCustomTypeJsonConvertor[T](),
+		JsonMapConvertor:    cconv.NewDefaultCustomTypeJsonConvertor[map[string]any](),
+		isTerminated:        make(chan struct{}),
+		nullAsEmpty:         true,
+		publicFields:        getJsonFieldPositions(reflect.TypeOf((*T)(nil)).Elem()),
+		publicKinds:         getJsonFieldKinds(reflect.TypeOf((*T)(nil)).Elem()),
+		preserveColumnOrder: true,
+		castFilterValues:    true,
+		schemaRetries:       3,
+		schemaRetryBackoff:  1000,
+		writeRetries:        2,
+		writeRetryBackoff:   100,
+		getAllWarnSize:      10000,
+		migrationsTable:     DefaultMigrationsTableName,
+		binaryEncoding:      BinaryEncodingNone,
+		unknownColumns:      UnknownColumnsKeep,
+		identifierQuote:     IdentifierQuoteBacktick,
+		returnOnWrite:       true,
+		cacheTimeout:        60000,
+		autoCreateSchema:    true,
 	}
 
 	c.DependencyResolver = cref.NewDependencyResolver()
@@ -239,8 +537,62 @@ func (c *MySqlPersistence[T]) Configure(ctx context.Context, config *cconf.Confi
 
 	c.TableName = config.GetAsStringWithDefault("collection", c.TableName)
 	c.TableName = config.GetAsStringWithDefault("table", c.TableName)
+	c.RawTableName = config.GetAsStringWithDefault("raw_table", c.RawTableName)
 	c.MaxPageSize = config.GetAsIntegerWithDefault("options.max_page_size", c.MaxPageSize)
+	c.DefaultPageSize = config.GetAsIntegerWithDefault("options.default_page_size", c.DefaultPageSize)
 	c.SchemaName = config.GetAsStringWithDefault("schema", c.SchemaName)
+	c.nullAsEmpty = config.GetAsBooleanWithDefault("options.null_as_empty", c.nullAsEmpty)
+	c.nestedSeparator = config.GetAsStringWithDefault("options.nested_separator", c.nestedSeparator)
+	c.strictColumns = config.GetAsBooleanWithDefault("options.strict_columns", c.strictColumns)
+	c.logParams = config.GetAsBooleanWithDefault("options.log_params", c.logParams)
+	c.logQueries = config.GetAsBooleanWithDefault("options.log_queries", c.logQueries)
+	c.recreateSchema = config.GetAsBooleanWithDefault("options.recreate_schema", c.recreateSchema)
+	c.strictInsert = config.GetAsBooleanWithDefault("options.strict_insert", c.strictInsert)
+	c.disableForeignKeys = config.GetAsBooleanWithDefault("options.disable_foreign_keys_on_delete", c.disableForeignKeys)
+	c.autoIncrementId = config.GetAsBooleanWithDefault("options.auto_increment_id", c.autoIncrementId)
+	c.preserveColumnOrder = config.GetAsBooleanWithDefault("options.preserve_column_order", c.preserveColumnOrder)
+	c.operationTimeout = config.GetAsIntegerWithDefault("options.operation_timeout_ms", c.operationTimeout)
+	c.slowQueryThreshold = config.GetAsIntegerWithDefault("options.slow_query_threshold_ms", c.slowQueryThreshold)
+	c.writeRetries = config.GetAsIntegerWithDefault("options.write_max_retries", c.writeRetries)
+	c.writeRetryBackoff = config.GetAsIntegerWithDefault("options.write_retry_backoff_ms", c.writeRetryBackoff)
+	c.schemaRetries = config.GetAsIntegerWithDefault("options.schema_max_retries", c.schemaRetries)
+	if c.schemaRetries < 1 {
+		c.schemaRetries = 1
+	}
+	c.schemaRetryBackoff = config.GetAsIntegerWithDefault("options.schema_retry_backoff_ms", c.schemaRetryBackoff)
+	c.castFilterValues = config.GetAsBooleanWithDefault("options.cast_filter_values", c.castFilterValues)
+	c.maxStatements = config.GetAsIntegerWithDefault("options.max_prepared_statements", c.maxStatements)
+	c.getAllWarnSize = config.GetAsIntegerWithDefault("options.get_all_warn_size", c.getAllWarnSize)
+	c.qualifySchema = config.GetAsBooleanWithDefault("options.qualify_schema", c.qualifySchema)
+	c.migrationsTable = config.GetAsStringWithDefault("options.migrations_table", c.migrationsTable)
+	c.approximateCount = config.GetAsBooleanWithDefault("options.approximate_count", c.approximateCount)
+	c.binaryEncoding = strings.ToLower(config.GetAsStringWithDefault("options.binary_encoding", c.binaryEncoding))
+	c.unknownColumns = strings.ToLower(config.GetAsStringWithDefault("options.unknown_columns", c.unknownColumns))
+	c.splitSetColumns = config.GetAsBooleanWithDefault("options.split_set_columns", c.splitSetColumns)
+	c.returnOnWrite = config.GetAsBooleanWithDefault("options.return_on_write", c.returnOnWrite)
+	c.versionColumn = config.GetAsStringWithDefault("options.version_column", c.versionColumn)
+	c.identifierQuote = strings.ToLower(config.GetAsStringWithDefault("options.identifier_quote", c.identifierQuote))
+	c.debug = config.GetAsBooleanWithDefault("options.debug", c.debug)
+	c.maxPacketSize = config.GetAsIntegerWithDefault("options.max_packet_size", c.maxPacketSize)
+	c.cacheTimeout = config.GetAsLongWithDefault("options.cache_timeout", c.cacheTimeout)
+	c.autoCreateSchema = config.GetAsBooleanWithDefault("options.auto_create_schema", c.autoCreateSchema)
+	c.dropProtectedColumns = config.GetAsBooleanWithDefault("options.drop_protected_columns", c.dropProtectedColumns)
+	c.updatableColumns = parseColumnList(config.GetAsString("options.updatable_columns"))
+	c.protectedColumns = parseColumnList(config.GetAsString("options.protected_columns"))
+
+	c.redactColumns = make(map[string]bool)
+	for _, column := range strings.Split(config.GetAsString("options.redact_columns"), ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column != "" {
+			c.redactColumns[column] = true
+		}
+	}
+	c.redactPositions = make(map[int]bool)
+	for _, position := range strings.Split(config.GetAsString("options.redact_positions"), ",") {
+		if index, ok := cconv.IntegerConverter.ToNullableInteger(strings.TrimSpace(position)); ok {
+			c.redactPositions[index] = true
+		}
+	}
 }
 
 // SetReferences to dependent components.
@@ -251,6 +603,15 @@ func (c *MySqlPersistence[T]) SetReferences(ctx context.Context, references cref
 
 	c.references = references
 	c.Logger.SetReferences(ctx, references)
+	c.Counters.SetReferences(ctx, references)
+	c.hasCounters = len(references.GetOptional(cref.NewDescriptor("*", "counters", "*", "*", "*"))) > 0
+	c.cache = nil
+	for _, ref := range references.GetOptional(cref.NewDescriptor("*", "cache", "*", "*", "1.0")) {
+		if cache, ok := ref.(ccache.ICache); ok {
+			c.cache = cache
+			break
+		}
+	}
 
 	// Get connection
 	c.DependencyResolver.SetReferences(ctx, references)
@@ -271,12 +632,97 @@ func (c *MySqlPersistence[T]) SetReferences(ctx context.Context, references cref
 // UnsetReferences (clears) previously set references to dependent components.
 func (c *MySqlPersistence[T]) UnsetReferences() {
 	c.Connection = nil
+	c.cache = nil
+}
+
+// cacheKey composes the key of the item in the cache from the table and the item id.
+func (c *MySqlPersistence[T]) cacheKey(id any) string {
+	if c.SchemaName != "" {
+		return c.SchemaName + "." + c.TableName + ":" + fmt.Sprint(id)
+	}
+	return c.TableName + ":" + fmt.Sprint(id)
+}
+
+// retrieveCached gets the item from the referenced cache.
+// Items stored as JSON, e.g. by distributed caches, are converted back to T.
+func (c *MySqlPersistence[T]) retrieveCached(ctx context.Context, correlationId string, id any) (item T, ok bool) {
+	if c.cache == nil {
+		return item, false
+	}
+	value, err := c.cache.Retrieve(ctx, correlationId, c.cacheKey(id))
+	if err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to retrieve %s from cache: %s", c.cacheKey(id), err.Error())
+		return item, false
+	}
+	switch v := value.(type) {
+	case T:
+		return c.cloneItem(v), true
+	case string:
+		item, err = c.JsonConvertor.FromJson(v)
+		return item, err == nil
+	}
+	return item, false
+}
+
+// storeCached puts a copy of the item to the referenced cache for the configured timeout.
+func (c *MySqlPersistence[T]) storeCached(ctx context.Context, correlationId string, id any, item T) {
+	if c.cache == nil {
+		return
+	}
+	key := c.cacheKey(id)
+	if _, err := c.cache.Store(ctx, correlationId, key, c.cloneItem(item), c.cacheTimeout); err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to store %s in cache: %s", key, err.Error())
+		return
+	}
+	c.cachedKeysLock.Lock()
+	defer c.cachedKeysLock.Unlock()
+	if c.cachedKeys == nil {
+		c.cachedKeys = make(map[string]bool)
+	}
+	c.cachedKeys[key] = true
+}
+
+// removeCached removes the item from the referenced cache after it is written,
+// so the next read gets it from the table.
+func (c *MySqlPersistence[T]) removeCached(ctx context.Context, correlationId string, id any) {
+	if c.cache == nil {
+		return
+	}
+	key := c.cacheKey(id)
+	if err := c.cache.Remove(ctx, correlationId, key); err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to remove %s from cache: %s", key, err.Error())
+	}
+	c.cachedKeysLock.Lock()
+	defer c.cachedKeysLock.Unlock()
+	delete(c.cachedKeys, key)
 }
 
+// removeAllCached removes all items of the table stored in the referenced cache by the persistence
+// after items are deleted by a filter, since ids of the deleted items are not known.
+func (c *MySqlPersistence[T]) removeAllCached(ctx context.Context, correlationId string) {
+	if c.cache == nil {
+		return
+	}
+	c.cachedKeysLock.Lock()
+	keys := c.cachedKeys
+	c.cachedKeys = nil
+	c.cachedKeysLock.Unlock()
+
+	for key := range keys {
+		if err := c.cache.Remove(ctx, correlationId, key); err != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to remove %s from cache: %s", key, err.Error())
+		}
+	}
+}
+
+// createConnection creates a local connection configured with the persistence configuration,
+// so the persistence defaults of options override the connection defaults.
 func (c *MySqlPersistence[T]) createConnection(ctx context.Context) *conn.MySqlConnection {
 	connection := conn.NewMySqlConnection()
 	if c.config != nil {
 		connection.Configure(ctx, c.config)
+	} else {
+		connection.Configure(ctx, c.defaultConfig)
 	}
 	if c.references != nil {
 		connection.SetReferences(ctx, c.references)
@@ -284,7 +730,8 @@ func (c *MySqlPersistence[T]) createConnection(ctx context.Context) *conn.MySqlC
 	return connection
 }
 
-// EnsureIndex adds index definition to create it on opening
+// EnsureIndex adds index definition to create it on opening.
+// Index key parts are ordered by field names, keys with values other than "1" are descending.
 //	Parameters:
 //		- keys index keys (fields)
 //		- options index options
@@ -298,51 +745,127 @@ func (c *MySqlPersistence[T]) EnsureIndex(name string, keys map[string]string, o
 		builder += " UNIQUE"
 	}
 
-	indexName := c.QuoteIdentifier(name)
-
-	if c.SchemaName != "" {
-		indexName = c.QuoteIdentifier(c.SchemaName) + "." + indexName
-	}
-
-	builder += " INDEX " + indexName + " ON " + c.QuotedTableName()
+	// MySQL index names belong to the table and can't be qualified with a schema
+	builder += " INDEX " + c.QuoteIdentifier(name) + " ON " + c.quotedBoundTableName()
 
 	if options["type"] != "" {
 		builder += " " + options["type"]
 	}
 
+	// Keys are ordered by names, since the order of the map is random
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
 	fields := ""
-	for key, _ := range keys {
+	for _, key := range names {
 		if fields != "" {
 			fields += ", "
 		}
-		fields += key
-		asc := keys[key]
-		if asc != "1" {
+		fields += c.QuoteIdentifier(key)
+		if keys[key] != "1" {
 			fields += " DESC"
 		}
 	}
 
-	builder += "(`" + fields + "`)"
+	builder += "(" + fields + ")"
+
+	c.EnsureSchema(builder)
+	c.schemaIndexes[builder] = name
+}
+
+// EnsureFunctionalIndex adds definition of an index over expressions to create it on opening.
+// Functional indexes are supported by MySQL 8.0.13 and later. Expressions that return TEXT or JSON,
+// like JSON_UNQUOTE, can't be indexed and must be cast to a sized type:
+// CAST(JSON_UNQUOTE(`data`->"$.key") AS CHAR(50))
+//	Parameters:
+//		- name index name
+//		- expressions index key expressions in the order of the index key parts
+//		- options index options
+func (c *MySqlPersistence[T]) EnsureFunctionalIndex(name string, expressions []string, options map[string]string) {
+	builder := "CREATE"
+	if options == nil {
+		options = make(map[string]string, 0)
+	}
+
+	if options["unique"] != "" {
+		builder += " UNIQUE"
+	}
+
+	builder += " INDEX " + c.QuoteIdentifier(name) + " ON " + c.quotedBoundTableName()
+
+	// Each key part expression must be enclosed in its own parentheses
+	parts := make([]string, len(expressions))
+	for i, expression := range expressions {
+		parts[i] = "(" + expression + ")"
+	}
+	builder += " (" + strings.Join(parts, ", ") + ")"
+
+	if options["type"] != "" {
+		builder += " USING " + options["type"]
+	}
+
+	c.EnsureSchema(builder)
+	c.schemaIndexes[builder] = name
+}
+
+// EnsureGeneratedColumn adds definition of a generated column to add it to the table on opening.
+// Stored columns are computed on writes and kept in the table, virtual ones are computed on reads.
+// Both kinds can be indexed, e.g. to index fields of JSON documents.
+//	Parameters:
+//		- name column name
+//		- columnType column data type, e.g. VARCHAR(50)
+//		- expression expression to compute the column value from other columns of the row
+//		- stored true to store the column, false to make it virtual
+func (c *MySqlPersistence[T]) EnsureGeneratedColumn(name string, columnType string, expression string, stored bool) {
+	builder := "ALTER TABLE " + c.quotedBoundTableName() + " ADD COLUMN " + c.QuoteIdentifier(name) +
+		" " + columnType + " AS (" + expression + ")"
+	if stored {
+		builder += " STORED"
+	} else {
+		builder += " VIRTUAL"
+	}
 
 	c.EnsureSchema(builder)
 }
 
 // DefineSchema a database schema for this persistence, have to call in child class
-// Override in child classes
+// Override in child classes. The schema is cleared before the call on opening,
+// so the statements are accumulated: call the parent DefineSchema first
+// and then add own statements.
 func (c *MySqlPersistence[T]) DefineSchema() {
-	c.ClearSchema()
 }
 
-// EnsureSchema adds a statement to schema definition
+// EnsureSchemaScript adds statements of a script separated by semicolons to schema definition.
+// The script is split by SplitStatements and the statements are executed one by one in CreateSchema.
+// They are not wrapped in a transaction because MySQL commits DDL statements implicitly.
+//	Parameters:
+//   - script statements to be added to the schema
+func (c *MySqlPersistence[T]) EnsureSchemaScript(script string) {
+	for _, schemaStatement := range SplitStatements(script) {
+		c.EnsureSchema(schemaStatement)
+	}
+}
+
+// EnsureSchema adds a statement to schema definition.
+// Statements which are already defined are skipped, so DefineSchema can be called several times.
 //	Parameters:
 //   - schemaStatement a statement to be added to the schema
 func (c *MySqlPersistence[T]) EnsureSchema(schemaStatement string) {
+	for _, statement := range c.schemaStatements {
+		if statement == schemaStatement {
+			return
+		}
+	}
 	c.schemaStatements = append(c.schemaStatements, schemaStatement)
 }
 
 // ClearSchema clears all auto-created objects
 func (c *MySqlPersistence[T]) ClearSchema() {
 	c.schemaStatements = []string{}
+	c.schemaIndexes = make(map[string]string)
 }
 
 // ConvertToPublic converts object value from internal to func (c * MySqlPersistence) format.
@@ -367,7 +890,12 @@ func (c *MySqlPersistence[T]) ConvertToPublic(rows *sql.Rows) (T, error) {
 	}
 
 	// result map
-	mapItem := make(map[string]string, len(columns))
+	mapItem := make(map[string]any, len(columns))
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return defaultValue, err
+	}
 
 	// get RawBytes from data
 	err = rows.Scan(scanArgs...)
@@ -376,7 +904,31 @@ func (c *MySqlPersistence[T]) ConvertToPublic(rows *sql.Rows) (T, error) {
 	}
 
 	for i := 0; i < len(columns); i++ {
-		// Here we can check if the value is nil (NULL value)
+		// NULL values are kept as JSON null if configured
+		if values[i] == nil && !c.nullAsEmpty {
+			mapItem[columns[i]] = nil
+			continue
+		}
+		// Integer ids are kept unquoted, e.g. ids generated by AUTO_INCREMENT columns
+		if values[i] != nil && c.numericId && strings.EqualFold(columns[i], "id") {
+			mapItem[columns[i]] = json.Number(values[i])
+			continue
+		}
+		// Binary values are encoded to survive JSON, fields of []byte type decode them back from base64
+		if values[i] != nil && c.binaryEncoding != BinaryEncodingNone && isBinaryColumn(columnTypes[i]) {
+			mapItem[columns[i]] = c.encodeBinary(values[i])
+			continue
+		}
+		// Members of SET values are read into arrays if configured
+		if values[i] != nil && c.splitSetColumns && isSetColumn(columnTypes[i]) {
+			mapItem[columns[i]] = splitSetValue(values[i])
+			continue
+		}
+		// Numbers are kept unquoted for numeric fields, e.g. integer-backed enums
+		if values[i] != nil && isNumericColumn(columnTypes[i]) && c.isNumericField(columns[i]) {
+			mapItem[columns[i]] = json.Number(values[i])
+			continue
+		}
 		mapItem[columns[i]] = string(values[i])
 	}
 
@@ -384,6 +936,11 @@ func (c *MySqlPersistence[T]) ConvertToPublic(rows *sql.Rows) (T, error) {
 		return defaultValue, err
 	}
 
+	if err = c.checkColumns(columns); err != nil {
+		return defaultValue, err
+	}
+	c.expandNestedColumns(mapItem)
+
 	jsonBuf, toJsonErr := cconv.JsonConverter.ToJson(mapItem)
 	if toJsonErr != nil {
 		return defaultValue, toJsonErr
@@ -395,387 +952,2608 @@ func (c *MySqlPersistence[T]) ConvertToPublic(rows *sql.Rows) (T, error) {
 
 }
 
-// ConvertFromPublic сonvert object value from func (c * MySqlPersistence) to internal format.
-//	Parameters:
-//		- value an object in func (c * MySqlPersistence) format to convert.
-//	Returns: converted object in internal format.
-func (c *MySqlPersistence[T]) ConvertFromPublic(value T) (map[string]any, error) {
-	buf, toJsonErr := cconv.JsonConverter.ToJson(value)
-	if toJsonErr != nil {
-		return nil, toJsonErr
+// convertToPublic converts the current row with the row scanner of the child class when it is provided
+// and with ConvertToPublic otherwise. Application errors of the conversion get the correlationId of the operation.
+func (c *MySqlPersistence[T]) convertToPublic(correlationId string, rows *sql.Rows) (item T, err error) {
+	if scanner, ok := c.Overrides.(IRowScanner[T]); ok {
+		item, err = scanner.ScanRow(rows)
+	} else {
+		item, err = c.Overrides.ConvertToPublic(rows)
 	}
+	if appErr, ok := err.(*cerr.ApplicationError); ok && appErr.CorrelationId == "" {
+		appErr.CorrelationId = correlationId
+	}
+	return item, err
+}
 
-	item, fromJsonErr := c.JsonMapConvertor.FromJson(buf)
-
-	return item, fromJsonErr
+// isNumericColumn checks if the column holds integer or decimal numbers.
+func isNumericColumn(columnType *sql.ColumnType) bool {
+	typeName := strings.ToUpper(columnType.DatabaseTypeName())
+	return strings.HasSuffix(typeName, "INT") || typeName == "DECIMAL" ||
+		typeName == "FLOAT" || typeName == "DOUBLE" || typeName == "YEAR"
 }
 
-// ConvertFromPublicPartial converts the given object from the public partial format.
-//	Parameters:
-//		- value the object to convert from the public partial format.
-//	Returns: the initial object.
-func (c *MySqlPersistence[T]) ConvertFromPublicPartial(value map[string]any) (map[string]any, error) {
-	buf, toJsonErr := cconv.JsonConverter.ToJson(value)
-	if toJsonErr != nil {
-		return nil, toJsonErr
+// isBinaryColumn checks if the column holds binary data, including spatial values in WKB format.
+func isBinaryColumn(columnType *sql.ColumnType) bool {
+	switch strings.ToUpper(columnType.DatabaseTypeName()) {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+		return true
 	}
+	return false
+}
 
-	item, fromJsonErr := c.JsonMapConvertor.FromJson(buf)
-	return item, fromJsonErr
+// isSetColumn checks if the column holds values of the SET type.
+func isSetColumn(columnType *sql.ColumnType) bool {
+	return strings.ToUpper(columnType.DatabaseTypeName()) == "SET"
 }
 
-func (c *MySqlPersistence[T]) QuoteIdentifier(value string) string {
-	if value == "" {
-		return value
-	}
-	if value[0] == '`' {
-		return value
+// splitSetValue splits a comma-separated value of a SET column into its members.
+// The empty set is read as an empty array.
+func splitSetValue(value []byte) []string {
+	if len(value) == 0 {
+		return []string{}
 	}
-	return "`" + value + "`"
+	return strings.Split(string(value), ",")
 }
 
-// QuotedTableName return quoted SchemaName with TableName ("schema"."table")
-func (c *MySqlPersistence[T]) QuotedTableName() string {
-	if len(c.SchemaName) > 0 {
-		return c.QuoteIdentifier(c.SchemaName) + "." + c.QuoteIdentifier(c.TableName)
+// encodeBinary encodes a binary value to a string in the configured encoding.
+func (c *MySqlPersistence[T]) encodeBinary(value []byte) string {
+	if c.binaryEncoding == BinaryEncodingHex {
+		return hex.EncodeToString(value)
 	}
-	return c.QuoteIdentifier(c.TableName)
+	return base64.StdEncoding.EncodeToString(value)
 }
 
-// IsOpen checks if the component is opened.
-//	Returns: true if the component has been opened and false otherwise.
-func (c *MySqlPersistence[T]) IsOpen() bool {
-	return c.opened
+// decodeBinary decodes a string in the configured encoding to a binary value.
+func (c *MySqlPersistence[T]) decodeBinary(value string) ([]byte, error) {
+	if c.binaryEncoding == BinaryEncodingHex {
+		return hex.DecodeString(value)
+	}
+	return base64.StdEncoding.DecodeString(value)
 }
 
-// IsTerminated checks if the wee need to terminate process before close component.
-//	Returns: true if you need terminate your processes.
-func (c *MySqlPersistence[T]) IsTerminated() bool {
-	select {
-	case _, ok := <-c.isTerminated:
-		if !ok {
-			return true
-		}
-	default:
-		return false
+// isIntegerDataType checks if the data type from the table metadata holds integer numbers.
+func isIntegerDataType(dataType string) bool {
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year":
+		return true
 	}
 	return false
 }
 
-// Open the component.
-//	Parameters:
-//		- ctx context.Context
-//		- correlationId (optional) transaction id to trace execution through call chain.
-//	Returns: error or nil no errors occurred.
-func (c *MySqlPersistence[T]) Open(ctx context.Context, correlationId string) (err error) {
-	if c.opened {
-		return nil
+// isBinaryDataType checks if the data type from the table metadata holds binary data.
+// Spatial types are not included as their values can't be bound as bytes.
+func isBinaryDataType(dataType string) bool {
+	switch dataType {
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return true
 	}
+	return false
+}
 
-	c.isTerminated = make(chan struct{})
+// isNumericField checks if values of the column can be unmarshalled as numbers.
+// Fields of string types are read as strings, other fields as numbers.
+// Columns that are not resolved to fields of T, e.g. when T is a map, are read as strings.
+func (c *MySqlPersistence[T]) isNumericField(column string) bool {
+	kind, ok := c.publicKinds[strings.ToLower(column)]
+	return ok && kind != reflect.String
+}
 
-	if c.Connection == nil {
-		c.Connection = c.createConnection(ctx)
-		c.localConnection = true
+// checkUnknownColumns handles written columns which are not found in the table according to the unknown_columns option:
+// they are kept, dropped or rejected with a BadRequest error listing them.
+// Columns of the table are read on opening, when they couldn't be read they are read again
+// and the check is skipped until they are read.
+func (c *MySqlPersistence[T]) checkUnknownColumns(ctx context.Context, correlationId string, objMap map[string]any) error {
+	if c.unknownColumns == UnknownColumnsKeep {
+		return nil
 	}
-
-	if c.localConnection {
-		err = c.Connection.Open(ctx, correlationId)
+	columnTypes := c.getColumnTypes(ctx, correlationId)
+	if columnTypes == nil {
+		return nil
 	}
 
-	if err == nil && c.Connection == nil {
-		err = cerr.NewInvalidStateError(correlationId, "NO_CONNECTION", "MySql connection is missing")
+	unknown := make([]string, 0)
+	for column := range objMap {
+		if _, ok := columnTypes[strings.ToLower(column)]; !ok {
+			unknown = append(unknown, column)
+		}
 	}
-
-	if err == nil && !c.Connection.IsOpen() {
-		err = cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "MySql connection is not opened")
+	if len(unknown) == 0 {
+		return nil
 	}
+	sort.Strings(unknown)
 
-	c.opened = false
-
-	if err != nil {
-		return err
+	if c.unknownColumns == UnknownColumnsError {
+		return cerr.NewBadRequestError(correlationId, "UNKNOWN_COLUMNS",
+			"Columns "+strings.Join(unknown, ", ")+" are not found in "+c.TableName).
+			WithDetails("columns", unknown)
 	}
-	c.Client = c.Connection.GetConnection()
-	c.DatabaseName = c.Connection.GetDatabaseName()
-
-	// Define database schema
-	c.Overrides.DefineSchema()
-
-	// Recreate objects
-	err = c.CreateSchema(ctx, correlationId)
-	if err != nil {
-		c.Client = nil
-		err = cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").WithCause(err)
-	} else {
-		c.opened = true
-		c.Logger.Debug(ctx, correlationId, "Connected to mysql database %s, collection %s", c.DatabaseName, c.QuotedTableName())
+	for _, column := range unknown {
+		delete(objMap, column)
 	}
-
-	return err
+	c.Logger.Debug(ctx, correlationId, "Dropped columns %s which are not found in %s", strings.Join(unknown, ", "), c.TableName)
+	return nil
 }
 
-// Close component and frees used resources.
-//	Parameters:
-//		- ctx context.Context
-//		- correlationId (optional) transaction id to trace execution through call chain.
-//	Returns: error or nil no errors occurred.
-func (c *MySqlPersistence[T]) Close(ctx context.Context, correlationId string) (err error) {
-	if !c.opened {
+// checkBinaryValues returns an error when a string written to a binary column can't be decoded from the binary_encoding.
+func (c *MySqlPersistence[T]) checkBinaryValues(correlationId string, objMap map[string]any) error {
+	if c.binaryEncoding == BinaryEncodingNone {
 		return nil
 	}
+	for column, value := range objMap {
+		str, ok := value.(string)
+		if !ok || !isBinaryDataType(c.columnType(column)) {
+			continue
+		}
+		if _, err := c.decodeBinary(str); err != nil {
+			return cerr.NewBadRequestError(correlationId, "INVALID_BINARY_VALUE",
+				"Value of column "+column+" is not encoded in "+c.binaryEncoding).
+				WithDetails("column", column).
+				WithDetails("binary_encoding", c.binaryEncoding).
+				WithCause(err)
+		}
+	}
+	return nil
+}
 
-	if c.Connection == nil {
-		return cerr.NewInvalidStateError(correlationId, "NO_CONNECTION", "MySql connection is missing")
+// parseColumnList parses a comma-separated list of columns into a set of lowercase names.
+// Returns nil when the list is empty.
+func parseColumnList(list string) map[string]bool {
+	var columns map[string]bool
+	for _, column := range strings.Split(list, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if columns == nil {
+			columns = make(map[string]bool)
+		}
+		columns[column] = true
 	}
+	return columns
+}
 
-	close(c.isTerminated)
-	if c.localConnection {
-		err = c.Connection.Close(ctx, correlationId)
+// filterUpdatableFields checks fields of a partial update against the updatable_columns and protected_columns.
+// Fields which are not allowed to update are dropped when drop_protected_columns is set, otherwise a BadRequest error is returned.
+// The version column is always allowed to check versions of updated items.
+func (c *MySqlPersistence[T]) filterUpdatableFields(ctx context.Context, correlationId string,
+	data cdata.AnyValueMap) (cdata.AnyValueMap, error) {
+	if c.updatableColumns == nil && c.protectedColumns == nil {
+		return data, nil
 	}
-	if err != nil {
-		return err
+
+	fields := data.Value()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
 	}
-	c.opened = false
-	c.Client = nil
-	c.Connection = nil
-	c.isTerminated = nil
-	return nil
+	sort.Strings(names)
+
+	allowed := make(map[string]any, len(fields))
+	for _, name := range names {
+		column := strings.ToLower(name)
+		if column == strings.ToLower(c.versionColumn) ||
+			((c.updatableColumns == nil || c.updatableColumns[column]) && !c.protectedColumns[column]) {
+			allowed[name] = fields[name]
+			continue
+		}
+		if !c.dropProtectedColumns {
+			return data, cerr.NewBadRequestError(correlationId, "PROTECTED_COLUMN",
+				"Column "+name+" of "+c.TableName+" is not allowed to update").
+				WithDetails("column", name)
+		}
+		c.Logger.Debug(ctx, correlationId, "Dropped protected column %s from partial update of %s", name, c.TableName)
+	}
+	return *cdata.NewAnyValueMap(allowed), nil
 }
 
-// Clear component state.
-//	Parameters:
-//		- ctx context.Context
-//		- correlationId 	(optional) transaction id to trace execution through call chain.
-//	Returns: error or nil no errors occured.
-func (c *MySqlPersistence[T]) Clear(ctx context.Context, correlationId string) error {
-	// Return error if collection is not set
-	if c.TableName == "" {
-		return errors.New("Table name is not defined")
+// expandNestedColumns moves values of columns with aliases like "address__city"
+// into nested objects of the item like {"address": {"city": ...}}.
+func (c *MySqlPersistence[T]) expandNestedColumns(item map[string]any) {
+	if c.nestedSeparator == "" {
+		return
 	}
-
-	rows, err := c.Client.QueryContext(ctx, "DELETE FROM "+c.QuotedTableName())
-	if err != nil {
-		return cerr.
-			NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").
-			WithCause(err)
+	for column, value := range item {
+		if !strings.Contains(column, c.nestedSeparator) {
+			continue
+		}
+		delete(item, column)
+
+		path := strings.Split(column, c.nestedSeparator)
+		parent := item
+		for _, key := range path[:len(path)-1] {
+			nested, ok := parent[key].(map[string]any)
+			if !ok {
+				nested = make(map[string]any)
+				parent[key] = nested
+			}
+			parent = nested
+		}
+		parent[path[len(path)-1]] = value
 	}
-	rows.Close()
-	return nil
 }
 
-func (c *MySqlPersistence[T]) CreateSchema(ctx context.Context, correlationId string) (err error) {
-	if len(c.schemaStatements) == 0 {
+// checkColumns returns an error in strict mode when some columns are not mapped to fields of T.
+// ConvertToPublic has no correlationId, so it is set in the error by convertToPublic.
+func (c *MySqlPersistence[T]) checkColumns(columns []string) error {
+	if !c.strictColumns || c.publicFields == nil {
 		return nil
 	}
 
-	// Check if table exist to determine weither to auto create objects
-	exists, err := c.checkTableExists(ctx)
-	if err != nil {
-		return err
+	unmapped := make([]string, 0)
+	for _, column := range columns {
+		// Nested columns are mapped to fields of the top level field
+		field := column
+		if c.nestedSeparator != "" {
+			field = strings.SplitN(column, c.nestedSeparator, 2)[0]
+		}
+		if _, ok := c.publicFields[strings.ToLower(field)]; !ok {
+			unmapped = append(unmapped, column)
+		}
 	}
-	if exists {
+	if len(unmapped) == 0 {
 		return nil
 	}
-	c.Logger.Debug(ctx, correlationId, "Table "+c.QuotedTableName()+" does not exist. Creating database objects...")
+	return cerr.NewInternalError("", "UNMAPPED_COLUMNS",
+		"Columns "+strings.Join(unmapped, ",")+" of "+c.TableName+" are not mapped to data fields").
+		WithDetails("columns", unmapped)
+}
 
-	for _, dml := range c.schemaStatements {
-		result, err := c.Client.QueryContext(ctx, dml)
-		if err != nil {
-			c.Logger.Error(ctx, correlationId, err, "Failed to autocreate database object")
-			return err
+// ConvertFromPublic сonvert object value from func (c * MySqlPersistence) to internal format.
+//	Parameters:
+//		- value an object in func (c * MySqlPersistence) format to convert.
+//	Returns: converted object in internal format.
+func (c *MySqlPersistence[T]) ConvertFromPublic(value T) (map[string]any, error) {
+	buf, toJsonErr := cconv.JsonConverter.ToJson(value)
+	if toJsonErr != nil {
+		return nil, toJsonErr
+	}
+
+	item, fromJsonErr := c.JsonMapConvertor.FromJson(buf)
+	if fromJsonErr != nil {
+		return nil, fromJsonErr
+	}
+	c.coerceIntegerValues(buf, item)
+
+	return item, nil
+}
+
+// ConvertFromPublicPartial converts the given object from the public partial format.
+// Values that can't be serialized to JSON, e.g. channels or functions, are rejected
+// with a BadRequest error naming the field.
+//	Parameters:
+//		- value the object to convert from the public partial format.
+//	Returns: the initial object.
+func (c *MySqlPersistence[T]) ConvertFromPublicPartial(value map[string]any) (map[string]any, error) {
+	buf, toJsonErr := cconv.JsonConverter.ToJson(value)
+	if toJsonErr != nil {
+		if err := checkSerializable("", "", value); err != nil {
+			return nil, err
+		}
+		return nil, toJsonErr
+	}
+
+	item, fromJsonErr := c.JsonMapConvertor.FromJson(buf)
+	if fromJsonErr != nil {
+		return nil, fromJsonErr
+	}
+	c.coerceIntegerValues(buf, item)
+
+	return item, nil
+}
+
+// checkSerializable finds a value that can't be serialized to JSON and returns a BadRequest error
+// naming its field by the path like "address.city" or "tags[1]". Nested maps and slices are checked recursively.
+func checkSerializable(correlationId string, path string, value any) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if err := checkSerializable(correlationId, fieldPath, v[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		for i, item := range v {
+			if err := checkSerializable(correlationId, path+"["+strconv.Itoa(i)+"]", item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return cerr.NewBadRequestError(correlationId, "UNSERIALIZABLE_FIELD",
+			"Field "+path+" can't be serialized to JSON: "+err.Error()).
+			WithDetails("field", path).
+			WithCause(err)
+	}
+	return nil
+}
+
+// coerceIntegerValues converts numbers decoded from JSON as float64 into integers
+// for columns of integer types, so large values like 64-bit ids are bound exactly.
+// The float64 values have already lost precision, so the numbers are decoded again.
+func (c *MySqlPersistence[T]) coerceIntegerValues(buf string, objMap map[string]any) {
+	columns := make([]string, 0)
+	for column, value := range objMap {
+		if _, ok := value.(float64); ok && isIntegerDataType(c.columnType(column)) {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(buf))
+	decoder.UseNumber()
+	var numbers map[string]any
+	if err := decoder.Decode(&numbers); err != nil {
+		return
+	}
+	for _, column := range columns {
+		if number, ok := numbers[column].(json.Number); ok {
+			objMap[column] = c.coerceIntegerValue(column, number)
+		}
+	}
+}
+
+// coerceIntegerValue converts a JSON number, a numeric string or a whole float64 bound to a column
+// of an integer type into int64, or uint64 for values above int64. Other values are returned as is.
+func (c *MySqlPersistence[T]) coerceIntegerValue(column string, value any) any {
+	if !isIntegerDataType(c.columnType(column)) {
+		return value
+	}
+	var number string
+	switch v := value.(type) {
+	case json.Number:
+		number = v.String()
+	case string:
+		number = v
+	case float64:
+		if v != math.Trunc(v) {
+			return value
+		}
+		number = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return value
+	}
+	if result, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return result
+	}
+	if result, err := strconv.ParseUint(number, 10, 64); err == nil {
+		return result
+	}
+	return value
+}
+
+// QuoteIdentifier quotes the identifier with backticks, or double quotes when the identifier_quote
+// option is "ansi", doubling embedded quotes according to MySQL rules, so the value can't break out of quoting.
+// Identifiers which are already properly quoted are returned as is.
+//	Parameters:
+//		- value an identifier to quote
+//	Returns: quoted identifier or empty string when the identifier is blank.
+func (c *MySqlPersistence[T]) QuoteIdentifier(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	quote := c.identifierQuoteChar()
+	if isQuotedIdentifier(value, quote) {
+		return value
+	}
+	return quote + strings.ReplaceAll(value, quote, quote+quote) + quote
+}
+
+// identifierQuoteChar gets the character that encloses identifiers in the configured quoting style.
+func (c *MySqlPersistence[T]) identifierQuoteChar() string {
+	if c.identifierQuote == IdentifierQuoteAnsi {
+		return "\""
+	}
+	return "`"
+}
+
+// isQuotedIdentifier checks if the value is enclosed in quotes and all embedded quotes are doubled.
+func isQuotedIdentifier(value string, quote string) bool {
+	if len(value) < 3 || value[:1] != quote || value[len(value)-1:] != quote {
+		return false
+	}
+	inner := value[1 : len(value)-1]
+	return !strings.Contains(strings.ReplaceAll(inner, quote+quote, ""), quote)
+}
+
+// validateIdentifiers checks the configured table and schema names.
+func (c *MySqlPersistence[T]) validateIdentifiers(correlationId string) error {
+	if strings.TrimSpace(c.TableName) == "" {
+		return cerr.NewConfigError(correlationId, "INVALID_IDENTIFIER", "MySql table name is not set").
+			WithDetails("table", c.TableName)
+	}
+	if c.SchemaName != "" && strings.TrimSpace(c.SchemaName) == "" {
+		return cerr.NewConfigError(correlationId, "INVALID_IDENTIFIER", "MySql schema name is blank").
+			WithDetails("schema", c.SchemaName)
+	}
+	if c.identifierQuote != IdentifierQuoteBacktick && c.identifierQuote != IdentifierQuoteAnsi {
+		return cerr.NewConfigError(correlationId, "INVALID_IDENTIFIER_QUOTE",
+			"Identifier quoting style "+c.identifierQuote+" is not supported").
+			WithDetails("identifier_quote", c.identifierQuote)
+	}
+	return nil
+}
+
+// ResolveCorrelationId returns the given correlationId or generates a new one
+// with CorrelationIdGenerator when it is empty.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: correlationId to use in the operation.
+func (c *MySqlPersistence[T]) ResolveCorrelationId(ctx context.Context, correlationId string) string {
+	if correlationId == "" && c.CorrelationIdGenerator != nil {
+		return c.CorrelationIdGenerator(ctx)
+	}
+	return correlationId
+}
+
+// RedactParams masks values of parameters bound to sensitive columns or positions,
+// so they can be safely written to logs.
+//	Parameters:
+//		- columns names of columns the parameters are bound to
+//		- values parameter values
+//	Returns: a copy of parameter values with masked sensitive values
+func (c *MySqlPersistence[T]) RedactParams(columns []string, values []any) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		if c.redactPositions[i] || (i < len(columns) && c.redactColumns[strings.ToLower(columns[i])]) {
+			result[i] = RedactedValue
+		} else {
+			result[i] = value
+		}
+	}
+	return result
+}
+
+func (c *MySqlPersistence[T]) traceParams(ctx context.Context, correlationId string, columns []string, values []any) {
+	if !c.logParams {
+		return
+	}
+	c.Logger.Trace(ctx, correlationId, "Bound parameters for %s: %v", c.TableName, c.RedactParams(columns, values))
+}
+
+// logQuery writes the query with number and types of its parameters.
+// Parameter values are not logged since they may contain sensitive data.
+func (c *MySqlPersistence[T]) logQuery(ctx context.Context, correlationId string, query string, args []any) {
+	if !c.logQueries {
+		return
+	}
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = fmt.Sprintf("%T", arg)
+	}
+	c.Logger.Debug(ctx, correlationId, "Executing query %s with %d parameters [%s]",
+		query, len(args), strings.Join(types, ","))
+}
+
+func (c *MySqlPersistence[T]) checkOpened(correlationId string) error {
+	if client, _ := c.getClient(); client == nil {
+		return c.notOpenedError(correlationId)
+	}
+	return nil
+}
+
+// notOpenedError creates an error returned when the persistence is not opened or closed while the operation is started.
+func (c *MySqlPersistence[T]) notOpenedError(correlationId string) error {
+	return cerr.NewInvalidStateError(correlationId, "NOT_OPENED", "MySql persistence is not opened")
+}
+
+// getClient gets the current primary pool and the prepared statements cache for it.
+// The pool of the connection is read on each call, so a pool replaced by reconnecting
+// the connection, also by another persistence sharing it, is used right away.
+// The statements cache of the replaced pool is closed and a new one is created.
+//	Returns: the pool or nil when the persistence is not opened, the statements cache or nil when it is disabled.
+func (c *MySqlPersistence[T]) getClient() (*sql.DB, *StatementCache) {
+	c.clientLock.RLock()
+	client, statements, connection := c.Client, c.statements, c.Connection
+	if !c.useConnectionPool {
+		connection = nil
+	}
+	c.clientLock.RUnlock()
+	if connection != nil {
+		client = connection.GetConnection()
+	}
+	// Views prepare statements in the cache of the persistence they are created from
+	if c.source != nil && connection != nil {
+		if _, sourceStatements := c.source.getClient(); sourceStatements != nil && sourceStatements.db == client {
+			return client, sourceStatements
+		}
+		return client, nil
+	}
+	if client == nil || statements == nil || statements.db == client {
+		return client, statements
+	}
+
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+	if c.statements != nil && c.statements.db != client {
+		c.statements.Close()
+		c.statements = NewStatementCache(client, c.maxStatements)
+	}
+	return client, c.statements
+}
+
+// getPool gets the pool to run the operation in with its prepared statements cache and connection.
+// Reads are routed to the current pool of the replica when it is configured,
+// statements are prepared only in the primary pool.
+func (c *MySqlPersistence[T]) getPool(operation string) (*sql.DB, *StatementCache, *conn.MySqlConnection) {
+	c.clientLock.RLock()
+	readConnection, connection := c.ReadConnection, c.Connection
+	c.clientLock.RUnlock()
+	if readConnection != nil && readOperations[operation] {
+		if readClient := readConnection.GetConnection(); readClient != nil {
+			return readClient, nil, readConnection
+		}
+	}
+	client, statements := c.getClient()
+	return client, statements, connection
+}
+
+// withOperationContext derives a context of the operation which carries the correlationId
+// and is limited by the configured operation timeout.
+func (c *MySqlPersistence[T]) withOperationContext(ctx context.Context, correlationId string) (context.Context, context.CancelFunc) {
+	ctx = ContextWithCorrelationId(ctx, correlationId)
+	if c.operationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(c.operationTimeout)*time.Millisecond)
+}
+
+// query executes a query that returns rows.
+// When the statements cache is enabled the query is executed as a prepared statement.
+func (c *MySqlPersistence[T]) query(ctx context.Context, correlationId string, operation string,
+	query string, args ...any) (*sql.Rows, error) {
+
+	// The persistence may be closed while the operation is started
+	client, _, connection := c.getPool(operation)
+	if client == nil {
+		return nil, c.notOpenedError(correlationId)
+	}
+
+	breaker := circuitBreakerOf(connection)
+	if breaker != nil && !breaker.Allow() {
+		return nil, c.circuitOpenError(correlationId, operation)
+	}
+
+	c.logQuery(ctx, correlationId, query, args)
+	done := c.instrument(ctx, operation)
+
+	run := func() (*sql.Rows, error) {
+		// The pool is read on each run to use the one replaced by reconnecting
+		client, statements, _ := c.getPool(operation)
+		if client == nil {
+			return nil, c.notOpenedError(correlationId)
+		}
+		if statements == nil {
+			return client.QueryContext(ctx, query, args...)
+		}
+		stmt, release, err := statements.Prepare(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		// Returned rows keep the statement open until they are closed
+		defer release()
+		return stmt.QueryContext(ctx, args...)
+	}
+
+	rows, err := run()
+	if err != nil && isClosedPoolError(err) {
+		// The pool was replaced by reconnecting while the operation was started
+		rows, err = run()
+	}
+	if err != nil && c.recoverSchema(ctx, correlationId, operation, err) {
+		rows, err = run()
+	}
+	for retry := 1; err != nil && c.waitForWriteRetry(ctx, correlationId, operation, err, retry); retry++ {
+		rows, err = run()
+	}
+
+	trackConnection(breaker, err)
+	done(err)
+	if err == nil {
+		c.trackRows(rows, query)
+	}
+	return rows, c.wrapError(ctx, correlationId, operation, err)
+}
+
+// trackRows remembers the cursor in the debug mode to check that it is closed before Close.
+// Cursors which were already closed are forgotten.
+func (c *MySqlPersistence[T]) trackRows(rows *sql.Rows, query string) {
+	if !c.debug || rows == nil {
+		return
+	}
+	c.openRowsLock.Lock()
+	defer c.openRowsLock.Unlock()
+	if c.openRows == nil {
+		c.openRows = make(map[*sql.Rows]string)
+	}
+	for openRows := range c.openRows {
+		if isRowsClosed(openRows) {
+			delete(c.openRows, openRows)
+		}
+	}
+	c.openRows[rows] = query
+}
+
+// reportOpenRows logs a warning for each tracked cursor which is still open and forgets all cursors.
+func (c *MySqlPersistence[T]) reportOpenRows(ctx context.Context, correlationId string) {
+	c.openRowsLock.Lock()
+	defer c.openRowsLock.Unlock()
+	for rows, query := range c.openRows {
+		if !isRowsClosed(rows) {
+			c.Logger.Warn(ctx, correlationId, "Cursor of query %s was not closed", query)
+		}
+	}
+	c.openRows = nil
+}
+
+// isRowsClosed checks if the cursor is closed explicitly or by reading all rows,
+// columns are not available after that.
+func isRowsClosed(rows *sql.Rows) bool {
+	_, err := rows.Columns()
+	return err != nil
+}
+
+// exec executes a query without returning any rows.
+func (c *MySqlPersistence[T]) exec(ctx context.Context, correlationId string, operation string,
+	query string, args ...any) (sql.Result, error) {
+
+	if client, _ := c.getClient(); client == nil {
+		return nil, c.notOpenedError(correlationId)
+	}
+
+	return c.execWith(ctx, correlationId, operation, query, args, func() (sql.Result, error) {
+		// The pool is read on each run to use the one replaced by reconnecting
+		client, statements := c.getClient()
+		if client == nil {
+			return nil, c.notOpenedError(correlationId)
+		}
+		if statements == nil {
+			return client.ExecContext(ctx, query, args...)
+		}
+		stmt, release, err := statements.Prepare(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return stmt.ExecContext(ctx, args...)
+	})
+}
+
+// execWith performs the common steps of executing the query: checks the circuit breaker,
+// logs and instruments the call, reruns it in the pool replaced by reconnecting,
+// re-creates a missing schema and wraps the returned error.
+//	Parameters:
+//		- run a function that executes the query in the current pool
+func (c *MySqlPersistence[T]) execWith(ctx context.Context, correlationId string, operation string,
+	query string, args []any, run func() (sql.Result, error)) (sql.Result, error) {
+
+	breaker := circuitBreakerOf(c.Connection)
+	if breaker != nil && !breaker.Allow() {
+		return nil, c.circuitOpenError(correlationId, operation)
+	}
+
+	c.logQuery(ctx, correlationId, query, args)
+	done := c.instrument(ctx, operation)
+
+	result, err := run()
+	if err != nil && isClosedPoolError(err) {
+		// The pool was replaced by reconnecting while the operation was started
+		result, err = run()
+	}
+	if err != nil && c.recoverSchema(ctx, correlationId, operation, err) {
+		result, err = run()
+	}
+	for retry := 1; err != nil && c.waitForWriteRetry(ctx, correlationId, operation, err, retry); retry++ {
+		result, err = run()
+	}
+
+	trackConnection(breaker, err)
+	done(err)
+	return result, c.wrapError(ctx, correlationId, operation, err)
+}
+
+// waitForWriteRetry checks if the write aborted by a deadlock or a lock wait timeout can be retried
+// and waits before the retry. The aborted statement is rolled back by the server, so it is safe to repeat it.
+//	Returns: true when the write shall be retried and false otherwise.
+func (c *MySqlPersistence[T]) waitForWriteRetry(ctx context.Context, correlationId string, operation string,
+	err error, retry int) bool {
+
+	if retry > c.writeRetries || !writeOperations[operation] || !isTransientError(err) {
+		return false
+	}
+
+	waitTime := time.Duration(c.writeRetryBackoff*retry) * time.Millisecond
+	c.Logger.Warn(ctx, correlationId, "MySql operation %s on %s is aborted by lock contention, retry in %s: %s",
+		operation, c.TableName, waitTime, err.Error())
+	select {
+	case <-time.After(waitTime):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// circuitBreakerOf gets the circuit breaker of the connection or nil when it is disabled.
+func circuitBreakerOf(connection *conn.MySqlConnection) *conn.MySqlCircuitBreaker {
+	if connection == nil {
+		return nil
+	}
+	return connection.GetCircuitBreaker()
+}
+
+// circuitOpenError creates an error returned without calling the database while the circuit breaker is open.
+func (c *MySqlPersistence[T]) circuitOpenError(correlationId string, operation string) error {
+	return cerr.NewConnectionError(correlationId, CircuitOpenErrorCode,
+		"MySql operation "+operation+" on "+c.TableName+" failed fast after repeated connection failures").
+		WithDetails("operation", operation).
+		WithDetails("table", c.TableName)
+}
+
+// trackConnection records the result of a call in the circuit breaker.
+// Calls failed for other reasons than connection failures prove the server is reachable.
+func trackConnection(breaker *conn.MySqlCircuitBreaker, err error) {
+	if breaker == nil {
+		return
+	}
+	if isConnectionError(err) {
+		breaker.Failure()
+	} else {
+		breaker.Success()
+	}
+}
+
+// isConnectionError checks if the error is caused by a failed connection to the server.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) || isClosedPoolError(err)
+}
+
+// isClosedPoolError checks if the query was started in a closed pool, e.g. replaced by Reconnect.
+// database/sql doesn't export the error, so it is compared by the message.
+func isClosedPoolError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "sql: database is closed")
+}
+
+// execDelete executes a delete query, in a transaction with disabled foreign key checks when it is configured.
+func (c *MySqlPersistence[T]) execDelete(ctx context.Context, correlationId string, operation string,
+	query string, args ...any) (sql.Result, error) {
+
+	if !c.disableForeignKeys {
+		return c.exec(ctx, correlationId, operation, query, args...)
+	}
+	return c.execWithoutForeignKeys(ctx, correlationId, operation, query, args...)
+}
+
+// execWithoutForeignKeys executes the query in a transaction with disabled foreign key checks.
+// FOREIGN_KEY_CHECKS is a session variable which is not restored on commit or rollback,
+// so the query runs on a dedicated connection and the checks are enabled again before it is released.
+// The connection is discarded when the checks can't be enabled.
+func (c *MySqlPersistence[T]) execWithoutForeignKeys(ctx context.Context, correlationId string, operation string,
+	query string, args ...any) (sql.Result, error) {
+
+	return c.execWith(ctx, correlationId, operation, query, args, func() (sql.Result, error) {
+		client, statements := c.getClient()
+		if client == nil {
+			return nil, c.notOpenedError(correlationId)
+		}
+		dbConn, err := client.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if _, resetErr := dbConn.ExecContext(context.Background(), "SET FOREIGN_KEY_CHECKS=1"); resetErr != nil {
+				dbConn.Raw(func(driverConn any) error { return driver.ErrBadConn })
+			}
+			dbConn.Close()
+		}()
+
+		tx, err := dbConn.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		if _, err = tx.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS=0"); err != nil {
+			return nil, err
+		}
+
+		var result sql.Result
+		if statements == nil {
+			result, err = tx.ExecContext(ctx, query, args...)
+		} else {
+			stmt, release, prepareErr := statements.Prepare(ctx, query)
+			if prepareErr != nil {
+				return nil, prepareErr
+			}
+			defer release()
+			result, err = tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
+
+// recoverSchema re-creates database objects when the table is missing,
+// for instance after reconnecting to a restored server.
+//	Returns: true if the objects were re-created and the operation can be retried.
+func (c *MySqlPersistence[T]) recoverSchema(ctx context.Context, correlationId string, operation string, err error) bool {
+	// Schema operations are not recovered to avoid recursion
+	if !c.recreateSchema || operation == "create_schema" || operation == "get_indexes" {
+		return false
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != errNoSuchTable {
+		return false
+	}
+
+	c.Logger.Warn(ctx, correlationId, "Table %s does not exist. Recreating database objects...", c.quotedBoundTableName())
+	if schemaErr := c.CreateSchema(ctx, correlationId); schemaErr != nil {
+		c.Logger.Error(ctx, correlationId, schemaErr, "Failed to recreate database objects")
+		return false
+	}
+	return true
+}
+
+// instrument starts timing of the operation named like "mysql.<table>.<operation>"
+// and returns a function to end the timing and count the failed operation.
+// Operations longer than the slow query threshold are logged as warnings.
+// Nothing is measured when no counters are referenced and the threshold is not set.
+func (c *MySqlPersistence[T]) instrument(ctx context.Context, operation string) func(err error) {
+	if !c.hasCounters && c.slowQueryThreshold <= 0 {
+		return func(err error) {}
+	}
+
+	name := "mysql." + c.TableName + "." + operation
+	start := time.Now()
+	var timing *ccount.CounterTiming
+	if c.hasCounters {
+		timing = c.Counters.BeginTiming(ctx, name)
+	}
+	return func(err error) {
+		if timing != nil {
+			timing.EndTiming(ctx)
+			if err != nil {
+				c.Counters.IncrementOne(ctx, name+".errors")
+			}
+		}
+		// Parameter values are not logged since they may contain sensitive data
+		duration := time.Since(start)
+		if c.slowQueryThreshold > 0 && duration >= time.Duration(c.slowQueryThreshold)*time.Millisecond {
+			correlationId, _ := GetCorrelationIdFromContext(ctx)
+			c.Logger.Warn(ctx, correlationId, "Slow MySql operation %s on %s took %d ms",
+				operation, c.TableName, duration.Milliseconds())
+		}
+	}
+}
+
+// wrapError converts an exceeded context deadline into a timeout error
+// with the operation and table in its details. Errors of the driver are converted only
+// when the connection is aborted or the query is cancelled after the deadline has passed,
+// other errors returned at the deadline are kept as is.
+func (c *MySqlPersistence[T]) wrapError(ctx context.Context, correlationId string, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !isDeadlineAbort(ctx, err) {
+		return err
+	}
+	return cerr.NewInvocationError(correlationId, TimeoutErrorCode,
+		"MySql operation "+operation+" on "+c.TableName+" exceeded the deadline").
+		WithDetails("operation", operation).
+		WithDetails("table", c.TableName).
+		WithCause(err)
+}
+
+// isDeadlineAbort checks if the error is returned by the driver for the connection aborted
+// or the query cancelled when the deadline of the context has passed.
+func isDeadlineAbort(ctx context.Context, err error) bool {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, context.Canceled)
+}
+
+// wrapQueryError converts an error of a query into an application error with the correlationId.
+// Application errors, e.g. timeouts, are returned as is, failed connections are returned as connection errors
+// and other errors as invocation errors with the operation and table in details.
+func (c *MySqlPersistence[T]) wrapQueryError(ctx context.Context, correlationId string, operation string, err error) error {
+	err = c.wrapError(ctx, correlationId, operation, err)
+	var appErr *cerr.ApplicationError
+	if err == nil || errors.As(err, &appErr) {
+		return err
+	}
+	if isConnectionError(err) {
+		return cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").
+			WithCause(err)
+	}
+	return cerr.NewInvocationError(correlationId, "QUERY_FAILED",
+		"MySql operation "+operation+" on "+c.TableName+" failed").
+		WithDetails("operation", operation).
+		WithDetails("table", c.TableName).
+		WithCause(err)
+}
+
+// wrapDuplicateError converts a duplicate key error into a conflict error.
+func (c *MySqlPersistence[T]) wrapDuplicateError(correlationId string, id any, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != errDuplicateEntry {
+		return err
+	}
+	return cerr.NewConflictError(correlationId, "DUPLICATE_KEY",
+		"Item with id "+fmt.Sprint(id)+" already exists in "+c.TableName).
+		WithDetails("id", id).
+		WithCause(err)
+}
+
+// isTransientError checks if the error is caused by lock contention and the operation can be retried.
+func isTransientError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) &&
+		(mysqlErr.Number == errLockWaitTimeout || mysqlErr.Number == errLockDeadlock)
+}
+
+// IsTimeoutError checks if the error was returned because an operation exceeded the context deadline.
+//	Parameters:
+//		- err an error to check
+//	Returns: true if the error is a timeout error and false otherwise.
+func IsTimeoutError(err error) bool {
+	var appErr *cerr.ApplicationError
+	return errors.As(err, &appErr) && appErr.Code == TimeoutErrorCode
+}
+
+// QuotedTableName return quoted SchemaName with TableName (`schema`.`table`)
+// or RawTableName as is when it is set
+func (c *MySqlPersistence[T]) QuotedTableName() string {
+	if c.RawTableName != "" {
+		return c.RawTableName
+	}
+	return c.QuotedTableNameFor(c.SchemaName, c.TableName)
+}
+
+// quotedBoundTableName returns quoted SchemaName with TableName even when RawTableName is set.
+// It is used in DDL statements, which create and change the table itself.
+func (c *MySqlPersistence[T]) quotedBoundTableName() string {
+	return c.QuotedTableNameFor(c.SchemaName, c.TableName)
+}
+
+// QuotedTableNameFor return quoted schema with table name (`schema`.`table`) for explicit target
+//	Parameters:
+//		- schema (optional) a schema name
+//		- table a table name
+//	Returns: quoted table name
+func (c *MySqlPersistence[T]) QuotedTableNameFor(schema string, table string) string {
+	if len(schema) > 0 {
+		return c.QuoteIdentifier(schema) + "." + c.QuoteIdentifier(table)
+	}
+	return c.QuoteIdentifier(table)
+}
+
+// WithSchema creates a view of the persistence bound to another schema,
+// e.g. to route requests to per-tenant schemas.
+// The view shares the connection with the persistence and doesn't create database objects.
+// It shall be opened after the persistence to bind to the connection.
+//	Parameters:
+//		- schema a schema name
+//	Returns: persistence view
+func (c *MySqlPersistence[T]) WithSchema(schema string) *MySqlPersistence[T] {
+	return c.WithTarget(schema, c.TableName)
+}
+
+// WithTable creates a view of the persistence bound to another table.
+// The view shares the connection with the persistence and doesn't create database objects.
+// It shall be opened after the persistence to bind to the connection.
+//	Parameters:
+//		- table a table name
+//	Returns: persistence view
+func (c *MySqlPersistence[T]) WithTable(table string) *MySqlPersistence[T] {
+	return c.WithTarget(c.SchemaName, table)
+}
+
+// WithTarget creates a view of the persistence bound to another schema and table.
+// The view shares the connection, the read replica, the prepared statements, the cache and
+// the performance counters with the persistence and doesn't create database objects.
+// It shall be opened after the persistence to bind to the connection.
+// Items are converted by the overrides of the persistence with its column metadata,
+// so the target table shall have the same layout as the table of the persistence.
+//	Parameters:
+//		- schema (optional) a schema name
+//		- table a table name
+//	Returns: persistence view
+func (c *MySqlPersistence[T]) WithTarget(schema string, table string) *MySqlPersistence[T] {
+	view := InheritMySqlPersistence[T](c.Overrides, table)
+	if c.config != nil {
+		view.Configure(context.Background(), c.config)
+	}
+	view.SchemaName = schema
+	view.TableName = table
+	view.JsonConvertor = c.JsonConvertor
+	view.JsonMapConvertor = c.JsonMapConvertor
+	view.Logger = c.Logger
+	view.CorrelationIdGenerator = c.CorrelationIdGenerator
+	view.numericId = c.numericId
+	view.Counters = c.Counters
+	view.hasCounters = c.hasCounters
+	view.cache = c.cache
+	view.Connection = c.Connection
+	c.clientLock.RLock()
+	view.ReadConnection, view.ReadClient = c.ReadConnection, c.ReadClient
+	c.clientLock.RUnlock()
+	view.view = true
+	view.source = c
+	return view
+}
+
+// IsOpen checks if the component is opened.
+//	Returns: true if the component has been opened and false otherwise.
+func (c *MySqlPersistence[T]) IsOpen() bool {
+	return c.opened
+}
+
+// IsTerminated checks if the wee need to terminate process before close component.
+//	Returns: true if you need terminate your processes.
+func (c *MySqlPersistence[T]) IsTerminated() bool {
+	select {
+	case _, ok := <-c.isTerminated:
+		if !ok {
+			return true
+		}
+	default:
+		return false
+	}
+	return false
+}
+
+// Open the component.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occurred.
+func (c *MySqlPersistence[T]) Open(ctx context.Context, correlationId string) (err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if c.opened {
+		return nil
+	}
+	if err = c.validateIdentifiers(correlationId); err != nil {
+		return err
+	}
+	if c.binaryEncoding != BinaryEncodingNone && c.binaryEncoding != BinaryEncodingBase64 && c.binaryEncoding != BinaryEncodingHex {
+		return cerr.NewConfigError(correlationId, "INVALID_BINARY_ENCODING",
+			"Binary encoding "+c.binaryEncoding+" is not supported").
+			WithDetails("binary_encoding", c.binaryEncoding)
+	}
+	if c.unknownColumns != UnknownColumnsKeep && c.unknownColumns != UnknownColumnsDrop && c.unknownColumns != UnknownColumnsError {
+		return cerr.NewConfigError(correlationId, "INVALID_UNKNOWN_COLUMNS",
+			"Handling of unknown columns "+c.unknownColumns+" is not supported").
+			WithDetails("unknown_columns", c.unknownColumns)
+	}
+
+	c.isTerminated = make(chan struct{})
+
+	if c.Connection == nil && !c.view {
+		c.Connection = c.createConnection(ctx)
+		c.localConnection = true
+	}
+
+	if c.localConnection {
+		err = c.Connection.Open(ctx, correlationId)
+	}
+
+	if err == nil && c.Connection == nil {
+		err = cerr.NewInvalidStateError(correlationId, "NO_CONNECTION", "MySql connection is missing")
+	}
+
+	if err == nil && !c.Connection.IsOpen() {
+		err = cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "MySql connection is not opened")
+	}
+
+	c.opened = false
+
+	if err != nil {
+		return err
+	}
+	c.setClient(c.Connection.GetConnection(), nil, false)
+	c.DatabaseName = c.Connection.GetDatabaseName()
+	if c.qualifySchema && c.SchemaName == "" {
+		c.SchemaName = c.DatabaseName
+	}
+
+	// Define database schema, views use the objects of the persistence they are created from
+	if !c.view {
+		c.ClearSchema()
+		c.Overrides.DefineSchema()
+	}
+
+	// Recreate objects or check the table when they are created elsewhere
+	if c.autoCreateSchema {
+		err = c.createSchemaWithRetries(ctx, correlationId)
+	} else {
+		var exists bool
+		exists, err = c.checkTableExists(ctx, correlationId)
+		if err == nil && !exists {
+			c.setClient(nil, nil, false)
+			if c.localConnection {
+				c.Connection.Close(ctx, correlationId)
+			}
+			return cerr.NewConfigError(correlationId, "TABLE_NOT_FOUND",
+				"Table "+c.quotedBoundTableName()+" is not found and auto_create_schema is disabled").
+				WithDetails("table", c.TableName)
+		}
+	}
+	if err == nil && c.source != nil {
+		// Views read in the replica of the persistence they are created from
+		c.source.clientLock.RLock()
+		readConnection := c.source.ReadConnection
+		c.source.clientLock.RUnlock()
+		c.setReadClient(readConnection)
+	} else if err == nil {
+		err = c.openReadConnection(ctx, correlationId)
+	}
+	if err != nil {
+		c.setClient(nil, nil, false)
+		err = cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").WithCause(err)
+	} else {
+		c.opened = true
+		// Queries run in the current pool of the connection from now on
+		client := c.Connection.GetConnection()
+		// Views prepare statements in the cache of the persistence they are created from
+		var statements *StatementCache
+		if c.maxStatements > 0 && c.source == nil {
+			statements = NewStatementCache(client, c.maxStatements)
+		}
+		c.setClient(client, statements, true)
+		c.loadUniqueColumns(ctx, correlationId)
+		// Column types are read again by getColumnTypes when they can't be read now
+		c.setColumnTypes(nil)
+		c.getColumnTypes(ctx, correlationId)
+		if c.maxPacketSize <= 0 {
+			c.loadMaxPacketSize(ctx, correlationId)
+		}
+		c.Logger.Debug(ctx, correlationId, "Connected to mysql database %s, collection %s", c.DatabaseName, c.QuotedTableName())
+	}
+
+	return err
+}
+
+// openReadConnection opens the connection to the read replica configured in the read_connection section.
+// The replica uses credentials from the read_credential section or the primary ones when it is not set.
+// Without the replica all queries are executed in the primary connection.
+func (c *MySqlPersistence[T]) openReadConnection(ctx context.Context, correlationId string) error {
+	c.setReadClient(nil)
+	if c.config == nil {
+		return nil
+	}
+	connectionConfig := c.config.GetSection("read_connection")
+	if connectionConfig.Len() == 0 {
+		return nil
+	}
+
+	credentialConfig := c.config.GetSection("read_credential")
+	if credentialConfig.Len() == 0 {
+		credentialConfig = c.config.GetSection("credential")
+	}
+	config := cconf.NewEmptyConfigParams()
+	config.AddSection("connection", connectionConfig)
+	config.AddSection("credential", credentialConfig)
+	config.AddSection("options", c.config.GetSection("options"))
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(ctx, config)
+	if c.references != nil {
+		connection.SetReferences(ctx, c.references)
+	}
+	if err := connection.Open(ctx, correlationId); err != nil {
+		return err
+	}
+	if !connection.IsOpen() {
+		return cerr.NewConnectionError(correlationId, "CONNECT_FAILED", "MySql read connection is not opened")
+	}
+
+	c.setReadClient(connection)
+	c.Logger.Debug(ctx, correlationId, "Connected to mysql read replica for collection %s", c.QuotedTableName())
+	return nil
+}
+
+// Close component and frees used resources.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occurred.
+func (c *MySqlPersistence[T]) Close(ctx context.Context, correlationId string) (err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if c.debug {
+		c.reportOpenRows(ctx, correlationId)
+	}
+	if !c.opened {
+		return nil
+	}
+
+	if c.Connection == nil {
+		return cerr.NewInvalidStateError(correlationId, "NO_CONNECTION", "MySql connection is missing")
+	}
+
+	close(c.isTerminated)
+	c.clientLock.RLock()
+	statements, readConnection := c.statements, c.ReadConnection
+	c.clientLock.RUnlock()
+	c.setClient(nil, nil, false)
+	if statements != nil {
+		if closeErr := statements.Close(); closeErr != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to close prepared statements: %s", closeErr.Error())
+		}
+	}
+	if readConnection != nil {
+		c.setReadClient(nil)
+	}
+	// The replica of a view belongs to the persistence it is created from
+	if readConnection != nil && c.source == nil {
+		if closeErr := readConnection.Close(ctx, correlationId); closeErr != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to close read connection: %s", closeErr.Error())
+		}
+	}
+	if c.localConnection {
+		err = c.Connection.Close(ctx, correlationId)
+	}
+	if err != nil {
+		return err
+	}
+	c.opened = false
+	c.Connection = nil
+	c.isTerminated = nil
+	return nil
+}
+
+// Reconnect replaces the connection pools of the persistence and its read replica with new ones,
+// see MySqlConnection.Reconnect. Queries read the pools of the connections on each call,
+// so persistence components sharing the connection use the new pool right away.
+// Operations started in the old pool complete in it or are run again in the new one.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occurred.
+func (c *MySqlPersistence[T]) Reconnect(ctx context.Context, correlationId string) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if !c.opened || c.Connection == nil {
+		return c.notOpenedError(correlationId)
+	}
+
+	if err := c.Connection.Reconnect(ctx, correlationId); err != nil {
+		return err
+	}
+	c.clientLock.RLock()
+	readConnection := c.ReadConnection
+	c.clientLock.RUnlock()
+	if readConnection != nil {
+		if err := readConnection.Reconnect(ctx, correlationId); err != nil {
+			return err
+		}
+	}
+
+	// The exported pools are updated for callers, the statements cache is replaced by getClient
+	c.clientLock.Lock()
+	c.Client = c.Connection.GetConnection()
+	if readConnection != nil {
+		c.ReadClient = readConnection.GetConnection()
+	}
+	c.clientLock.Unlock()
+	c.getClient()
+	c.Logger.Debug(ctx, correlationId, "Reconnected to mysql database %s, collection %s", c.DatabaseName, c.QuotedTableName())
+	return nil
+}
+
+// setClient sets the primary pool and its statements cache.
+//	Parameters:
+//		- useConnectionPool true to run queries in the current pool of the connection instead of the client
+func (c *MySqlPersistence[T]) setClient(client *sql.DB, statements *StatementCache, useConnectionPool bool) {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+	c.Client = client
+	c.statements = statements
+	c.useConnectionPool = useConnectionPool
+}
+
+// setReadClient sets the connection of the read replica, nil to route reads to the primary pool.
+func (c *MySqlPersistence[T]) setReadClient(connection *conn.MySqlConnection) {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+	c.ReadConnection = connection
+	c.ReadClient = nil
+	if connection != nil {
+		c.ReadClient = connection.GetConnection()
+	}
+}
+
+// SetClient sets a connection pool created elsewhere, e.g. a pool shared with other components
+// or a mock in unit tests. The persistence can be used right away without opening it:
+// database objects are not created, table metadata is not loaded and read replicas are not used.
+// The pool is owned by the caller and is not closed by Close.
+//	Parameters:
+//		- client a connection pool to execute queries in
+//		- databaseName a name of the database the pool is connected to
+func (c *MySqlPersistence[T]) SetClient(client *sql.DB, databaseName string) {
+	var statements *StatementCache
+	if client != nil && c.maxStatements > 0 {
+		statements = NewStatementCache(client, c.maxStatements)
+	}
+	c.setClient(client, statements, false)
+	c.setReadClient(nil)
+	c.DatabaseName = databaseName
+	if c.qualifySchema && c.SchemaName == "" {
+		c.SchemaName = c.DatabaseName
+	}
+	c.uniqueColumns = nil
+	c.setColumnTypes(nil)
+}
+
+// Clear component state.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId 	(optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occured.
+func (c *MySqlPersistence[T]) Clear(ctx context.Context, correlationId string) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
+	// Return error if collection is not set
+	if c.TableName == "" {
+		return errors.New("Table name is not defined")
+	}
+	// Items may be deleted even when the query fails
+	defer c.removeAllCached(ctx, correlationId)
+
+	result, err := c.exec(ctx, correlationId, "clear", "DELETE FROM "+c.QuotedTableName())
+	if err != nil {
+		if IsTimeoutError(err) {
+			return err
+		}
+		return cerr.
+			NewConnectionError(correlationId, "CONNECT_FAILED", "Connection to mysql failed").
+			WithCause(err)
+	}
+	count, _ := result.RowsAffected()
+	c.Logger.Trace(ctx, correlationId, "Cleared %d items in %s", count, c.TableName)
+	return nil
+}
+
+// DeleteAll deletes all data items from the table. Unlike Clear, which is meant for tests,
+// it requires an explicit confirmation, so a zero-value flag doesn't delete data by accident.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId 	(optional) transaction id to trace execution through call chain.
+//		- confirm			true to confirm deleting all data items.
+//	Returns: error or nil no errors occured.
+func (c *MySqlPersistence[T]) DeleteAll(ctx context.Context, correlationId string, confirm bool) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if !confirm {
+		return cerr.NewBadRequestError(correlationId, "DELETE_NOT_CONFIRMED",
+			"Deleting all items from "+c.TableName+" is not confirmed").
+			WithDetails("table", c.TableName)
+	}
+	return c.Clear(ctx, correlationId)
+}
+
+// Truncate removes all items from the table with TRUNCATE TABLE and resets AUTO_INCREMENT counters.
+// It is much faster than Clear on large tables, but it can't be rolled back
+// and fails on tables referenced by foreign keys, where Clear shall be used.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId 	(optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occured.
+func (c *MySqlPersistence[T]) Truncate(ctx context.Context, correlationId string) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
+
+	defer c.removeAllCached(ctx, correlationId)
+
+	_, err := c.exec(ctx, correlationId, "truncate", "TRUNCATE TABLE "+c.quotedBoundTableName())
+	if err != nil {
+		return err
+	}
+	c.Logger.Trace(ctx, correlationId, "Truncated %s", c.TableName)
+	return nil
+}
+
+// CreateSchema creates database objects defined by the schema statements.
+// If the table already exists only the missing indexes are created.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: error or nil no errors occurred.
+func (c *MySqlPersistence[T]) CreateSchema(ctx context.Context, correlationId string) (err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
+	if len(c.schemaStatements) == 0 {
+		return nil
+	}
+
+	// Check if table exist to determine weither to auto create objects
+	exists, err := c.checkTableExists(ctx, correlationId)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return c.createMissingIndexes(ctx, correlationId)
+	}
+	c.Logger.Debug(ctx, correlationId, "Table "+c.quotedBoundTableName()+" does not exist. Creating database objects...")
+
+	for _, dml := range c.schemaStatements {
+		result, err := c.query(ctx, correlationId, "create_schema", dml)
+		if err != nil {
+			c.Logger.Error(ctx, correlationId, err, "Failed to autocreate database object")
+			return err
+		}
+		result.Close()
+	}
+	return nil
+}
+
+// createSchemaWithRetries creates database objects retrying on transient lock errors,
+// which are common when many instances start together.
+func (c *MySqlPersistence[T]) createSchemaWithRetries(ctx context.Context, correlationId string) error {
+	for attempt := 1; ; attempt++ {
+		err := c.CreateSchema(ctx, correlationId)
+		if err == nil || attempt >= c.schemaRetries || !isTransientError(err) {
+			return err
+		}
+
+		waitTime := time.Duration(c.schemaRetryBackoff*attempt) * time.Millisecond
+		c.Logger.Warn(ctx, correlationId, "Failed to create database objects for %s, retry in %s: %s",
+			c.quotedBoundTableName(), waitTime, err.Error())
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (c *MySqlPersistence[T]) createMissingIndexes(ctx context.Context, correlationId string) error {
+	if len(c.schemaIndexes) == 0 {
+		return nil
+	}
+
+	indexes, err := c.GetIndexes(ctx, correlationId)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		existing[index] = true
+	}
+
+	for _, dml := range c.schemaStatements {
+		name, ok := c.schemaIndexes[dml]
+		if !ok || existing[name] {
+			continue
+		}
+		c.Logger.Debug(ctx, correlationId, "Index "+name+" does not exist in "+c.quotedBoundTableName()+". Creating...")
+		_, err := c.exec(ctx, correlationId, "create_schema", dml)
+		if err != nil {
+			c.Logger.Error(ctx, correlationId, err, "Failed to autocreate index "+name)
+			return err
+		}
+		existing[name] = true
+	}
+	return nil
+}
+
+// GetIndexes gets names of the indexes defined for the table.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//	Returns: a list of index names or error.
+func (c *MySqlPersistence[T]) GetIndexes(ctx context.Context, correlationId string) ([]string, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
+	condition, args := c.tableMetadataCondition()
+	query := "SELECT DISTINCT INDEX_NAME FROM information_schema.STATISTICS WHERE " + condition
+
+	rows, err := c.query(ctx, correlationId, "get_indexes", query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, name)
+	}
+
+	c.Logger.Trace(ctx, correlationId, "Retrieved %d indexes from %s", len(indexes), c.TableName)
+	return indexes, c.wrapError(ctx, correlationId, "get_indexes", rows.Err())
+}
+
+func (c *MySqlPersistence[T]) tableMetadataCondition() (string, []any) {
+	if c.SchemaName != "" {
+		return "TABLE_NAME=? AND TABLE_SCHEMA=?", []any{c.TableName, c.SchemaName}
+	}
+	return "TABLE_NAME=? AND TABLE_SCHEMA=DATABASE()", []any{c.TableName}
+}
+
+// loadColumnTypes reads data types of the table columns. When they can't be read
+// the column types are left unset to read them again on demand.
+func (c *MySqlPersistence[T]) loadColumnTypes(ctx context.Context, correlationId string) {
+	condition, args := c.tableMetadataCondition()
+	query := "SELECT COLUMN_NAME, DATA_TYPE FROM information_schema.COLUMNS WHERE " + condition
+
+	rows, err := c.query(ctx, correlationId, "get_columns", query, args...)
+	if err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to read column types of %s: %s", c.TableName, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	columnTypes := make(map[string]string)
+	for rows.Next() {
+		var column, dataType string
+		if err := rows.Scan(&column, &dataType); err != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to read column types of %s: %s", c.TableName, err.Error())
+			return
+		}
+		columnTypes[strings.ToLower(column)] = strings.ToLower(dataType)
+	}
+	if err := rows.Err(); err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to read column types of %s: %s", c.TableName, err.Error())
+		return
+	}
+	if len(columnTypes) > 0 {
+		c.setColumnTypes(columnTypes)
+	}
+}
+
+// getColumnTypes gets data types of the table columns by lowercase column names.
+// The types are read from the table when they are not read yet, concurrent calls read them once.
+//	Returns: the column types or nil when they can't be read.
+func (c *MySqlPersistence[T]) getColumnTypes(ctx context.Context, correlationId string) map[string]string {
+	if columnTypes := c.loadedColumnTypes(); columnTypes != nil {
+		return columnTypes
+	}
+
+	c.columnTypesLoad.Lock()
+	defer c.columnTypesLoad.Unlock()
+	if columnTypes := c.loadedColumnTypes(); columnTypes != nil {
+		return columnTypes
+	}
+	c.loadColumnTypes(ctx, correlationId)
+	return c.loadedColumnTypes()
+}
+
+// loadedColumnTypes gets data types of the table columns read before, nil when they are not read.
+func (c *MySqlPersistence[T]) loadedColumnTypes() map[string]string {
+	c.columnTypesLock.RLock()
+	defer c.columnTypesLock.RUnlock()
+	return c.columnTypes
+}
+
+// setColumnTypes sets data types of the table columns, nil to read them again.
+func (c *MySqlPersistence[T]) setColumnTypes(columnTypes map[string]string) {
+	c.columnTypesLock.Lock()
+	defer c.columnTypesLock.Unlock()
+	c.columnTypes = columnTypes
+}
+
+// columnType gets the lowercase data type of the column or an empty string
+// when the column types are not read or the column is not found.
+func (c *MySqlPersistence[T]) columnType(column string) string {
+	c.columnTypesLock.RLock()
+	defer c.columnTypesLock.RUnlock()
+	return c.columnTypes[strings.ToLower(column)]
+}
+
+// loadMaxPacketSize reads the max_allowed_packet of the server to limit the size of batch statements.
+// The value is kept until the persistence is configured with another one.
+func (c *MySqlPersistence[T]) loadMaxPacketSize(ctx context.Context, correlationId string) {
+	rows, err := c.query(ctx, correlationId, "get_max_packet", "SELECT @@max_allowed_packet")
+	if err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to read max_allowed_packet: %s", err.Error())
+		return
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var size int
+		if err := rows.Scan(&size); err != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to read max_allowed_packet: %s", err.Error())
+			return
+		}
+		c.maxPacketSize = size
+	}
+}
+
+func (c *MySqlPersistence[T]) loadUniqueColumns(ctx context.Context, correlationId string) {
+	c.uniqueColumns = nil
+
+	condition, args := c.tableMetadataCondition()
+	query := "SELECT INDEX_NAME, COLUMN_NAME FROM information_schema.STATISTICS WHERE " + condition + " AND NON_UNIQUE=0"
+
+	rows, err := c.query(ctx, correlationId, "get_indexes", query, args...)
+	if err != nil {
+		c.Logger.Warn(ctx, correlationId, "Failed to read unique keys of %s: %s", c.TableName, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	indexColumns := make(map[string][]string)
+	for rows.Next() {
+		var index, column string
+		if err := rows.Scan(&index, &column); err != nil {
+			c.Logger.Warn(ctx, correlationId, "Failed to read unique keys of %s: %s", c.TableName, err.Error())
+			return
+		}
+		indexColumns[index] = append(indexColumns[index], column)
+	}
+	if rows.Err() != nil {
+		return
+	}
+
+	// Only a single-column key makes its column unique by itself
+	uniqueColumns := make(map[string]bool)
+	for _, columns := range indexColumns {
+		if len(columns) == 1 {
+			uniqueColumns[columns[0]] = true
+		}
+	}
+	if len(uniqueColumns) == 0 {
+		c.Logger.Warn(ctx, correlationId, "Table %s has no unique keys, upserts can not detect existing rows", c.TableName)
+	}
+	c.uniqueColumns = uniqueColumns
+}
+
+// isUniqueColumn checks if the column is covered by a unique key.
+// When table metadata is unknown the column is considered to be unique.
+func (c *MySqlPersistence[T]) isUniqueColumn(column string) bool {
+	if c.uniqueColumns == nil {
+		return true
+	}
+	return c.uniqueColumns[column]
+}
+
+func (c *MySqlPersistence[T]) checkTableExists(ctx context.Context, correlationId string) (bool, error) {
+	// Check if table exist to determine either to auto create objects
+	condition, args := c.tableMetadataCondition()
+	query := "SELECT COUNT(*) FROM information_schema.TABLES WHERE " + condition
+	result, err := c.query(ctx, correlationId, "create_schema", query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer result.Close()
+
+	var count int64
+	if result.Next() {
+		if err := result.Scan(&count); err != nil {
+			return false, err
+		}
+	}
+	return count > 0, result.Err()
+}
+
+// GenerateColumns generates a list of column names to use in SQL statements like: "column1,column2,column3"
+//	Parameters:
+//		- columns an array with column values
+//	Returns: a generated list of column names
+func (c *MySqlPersistence[T]) GenerateColumns(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	for _, item := range columns {
+		if builder.String() != "" {
+			builder.WriteString(",")
+		}
+		builder.WriteString(c.QuoteIdentifier(item))
+	}
+	return builder.String()
+
+}
+
+// GenerateParameters generates a list of value parameters to use in SQL statements like: "?,?,?"
+//	Parameters:
+//		- values an array with column values or a key-value map
+//	Returns: a generated list of value parameters
+func (c *MySqlPersistence[T]) GenerateParameters(valuesCount int) string {
+	if valuesCount <= 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	for index := 1; index <= valuesCount; index++ {
+		if builder.String() != "" {
+			builder.WriteString(",")
+		}
+		builder.WriteString("?")
+	}
+
+	return builder.String()
+}
+
+// GenerateSetParameters generates a list of column sets to use in UPDATE statements like: column1=?,column2=?
+//	Parameters:
+//		- values an array with column values or a key-value map
+//	Returns: a generated list of column sets
+func (c *MySqlPersistence[T]) GenerateSetParameters(columns []string) string {
+
+	if len(columns) == 0 {
+		return ""
+	}
+	setParamsBuf := strings.Builder{}
+	index := 1
+	for i := range columns {
+		if setParamsBuf.String() != "" {
+			setParamsBuf.WriteString(",")
+		}
+		setParamsBuf.WriteString(c.QuoteIdentifier(columns[i]) + "=?")
+		index++
+	}
+	return setParamsBuf.String()
+}
+
+// GenerateUpsertParameters generates a list of column sets to use in ON DUPLICATE KEY UPDATE clauses
+// that refer to the inserted values like: column1=VALUES(column1),column2=VALUES(column2)
+//	Parameters:
+//		- columns an array with column names
+//	Returns: a generated list of column sets
+func (c *MySqlPersistence[T]) GenerateUpsertParameters(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	for _, column := range columns {
+		if builder.Len() > 0 {
+			builder.WriteString(",")
+		}
+		quotedColumn := c.QuoteIdentifier(column)
+		builder.WriteString(quotedColumn + "=VALUES(" + quotedColumn + ")")
+	}
+	return builder.String()
+}
+
+// GenerateLikeFilter generates a "contains" filter for a text column like: `column` LIKE ? ESCAPE '!'
+// LIKE metacharacters in the search term are escaped with "!", so the term is matched literally.
+// The returned parameter shall be passed in the filter args.
+//	Parameters:
+//		- column a column name
+//		- term a search term
+//	Returns: a generated filter and its parameter value
+func (c *MySqlPersistence[T]) GenerateLikeFilter(column string, term string) (string, any) {
+	term = likeEscaper.Replace(term)
+	return c.QuoteIdentifier(column) + " LIKE ? ESCAPE '!'", "%" + term + "%"
+}
+
+// GenerateEqualFilter generates a filter comparing a column to a value like: `column`=?
+// The returned parameter shall be passed in the filter args.
+//	Parameters:
+//		- column a column name
+//		- value a value to compare with
+//	Returns: a generated filter and its parameter value cast to the column type
+func (c *MySqlPersistence[T]) GenerateEqualFilter(column string, value any) (string, any) {
+	return c.QuoteIdentifier(column) + "=?", c.CastFilterValue(column, value)
+}
+
+// GenerateCollatedFilter generates a filter comparing a column to a value using the given collation
+// like: `column`=? COLLATE utf8mb4_general_ci. It allows, for instance, case-insensitive matching
+// of values in a column with case-sensitive collation. Characters not allowed in collation names are removed.
+// To change comparisons in all queries define the column collation in the schema instead:
+//
+//	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50) COLLATE utf8mb4_general_ci)")
+//
+//	Parameters:
+//		- column a column name
+//		- value a value to compare with
+//		- collation a collation name compatible with the connection character set
+//	Returns: a generated filter and its parameter value cast to the column type
+func (c *MySqlPersistence[T]) GenerateCollatedFilter(column string, value any, collation string) (string, any) {
+	filter, param := c.GenerateEqualFilter(column, value)
+	collation = collationSanitizer.ReplaceAllString(collation, "")
+	if collation == "" {
+		return filter, param
+	}
+	return filter + " COLLATE " + collation, param
+}
+
+// GenerateExistsFilter generates a filter matching items that have at least one related row in another table
+// of the same schema like: EXISTS (SELECT 1 FROM `tags` WHERE `tags`.`dummy_id`=`dummies`.`id` AND (`tag`=?))
+//	Parameters:
+//		- table a name of the related table
+//		- foreignKey a column of the related table that refers to item ids
+//		- condition (optional) an additional condition for the related rows
+//		- args (optional) values of parameters used in the condition
+//	Returns: a generated filter and its parameter values
+func (c *MySqlPersistence[T]) GenerateExistsFilter(table string, foreignKey string, condition string, args ...any) (string, []any) {
+	relatedTable := c.QuotedTableNameFor(c.SchemaName, table)
+
+	filter := "EXISTS (SELECT 1 FROM " + relatedTable +
+		" WHERE " + relatedTable + "." + c.QuoteIdentifier(foreignKey) + "=" + c.QuotedTableName() + "." + c.QuoteIdentifier("id")
+	if len(condition) > 0 {
+		filter += " AND (" + condition + ")"
+	}
+	filter += ")"
+	return filter, args
+}
+
+// GenerateFilter generates a condition over the given columns from filter parameters
+// like: `age`>=? AND `age`<=? AND `key` IN (?,?)
+// Parameters are matched to columns ignoring case and underscores, e.g. CreateTime matches create_time:
+//	- <column>      matches values equal to the parameter, commas in it are a part of the value
+//	- <column>In    matches values equal to one of the values of a comma-separated list, e.g. key_in
+//	- From<column>  matches values greater than or equal to the parameter
+//	- To<column>    matches values less than or equal to the parameter
+// Filter parameters keep values as strings, so lists are passed only with the In suffix.
+// Parameters of other columns are ignored, so callers can filter only by the listed columns.
+// The returned parameters are cast to the column types like in GenerateEqualFilter and shall be passed in the filter args.
+//	Parameters:
+//		- filter filter parameters
+//		- columns names of columns to filter by
+//	Returns: a generated filter, empty when no parameters are set, and its parameter values
+func (c *MySqlPersistence[T]) GenerateFilter(filter cdata.FilterParams, columns ...string) (string, []any) {
+	params := make(map[string]string)
+	for _, key := range filter.Keys() {
+		if value := filter.GetAsString(key); value != "" {
+			params[normalizeFilterName(key)] = value
+		}
+	}
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	for _, column := range columns {
+		name := normalizeFilterName(column)
+		quotedColumn := c.QuoteIdentifier(column)
+
+		if value, ok := params[name]; ok {
+			condition, arg := c.GenerateEqualFilter(column, value)
+			conditions = append(conditions, condition)
+			args = append(args, arg)
+		}
+		if value, ok := params[name+"in"]; ok {
+			values := strings.Split(value, ",")
+			conditions = append(conditions, quotedColumn+" IN ("+c.GenerateParameters(len(values))+")")
+			for _, item := range values {
+				args = append(args, c.CastFilterValue(column, strings.TrimSpace(item)))
+			}
+		}
+		if value, ok := params["from"+name]; ok {
+			conditions = append(conditions, quotedColumn+">=?")
+			args = append(args, c.CastFilterValue(column, value))
+		}
+		if value, ok := params["to"+name]; ok {
+			conditions = append(conditions, quotedColumn+"<=?")
+			args = append(args, c.CastFilterValue(column, value))
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// normalizeFilterName converts names of filter parameters and columns to match them ignoring case and underscores.
+func normalizeFilterName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// CastFilterValue converts a filter value to the type of the column taken from the table metadata,
+// so comparisons use the column type and its indexes. Values of unknown columns or
+// values that can't be converted are returned as is.
+//	Parameters:
+//		- column a column name
+//		- value a value to convert
+//	Returns: the converted value
+func (c *MySqlPersistence[T]) CastFilterValue(column string, value any) any {
+	if !c.castFilterValues || value == nil {
+		return value
+	}
+
+	var result any
+	var ok bool
+	switch c.columnType(column) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year":
+		result, ok = cconv.LongConverter.ToNullableLong(value)
+	case "decimal", "numeric", "float", "double", "real":
+		result, ok = cconv.DoubleConverter.ToNullableDouble(value)
+	case "date", "datetime", "timestamp":
+		result, ok = cconv.DateTimeConverter.ToNullableDateTime(value)
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		result, ok = cconv.StringConverter.ToNullableString(value)
+	}
+	if !ok {
+		return value
+	}
+	return result
+}
+
+// GenerateColumnsAndValues generates a list of column parameters.
+// Strings written to binary columns are decoded from the binary_encoding to bytes unless it is "none".
+//	Parameters:
+//		- values an array with column values or a key-value map
+//	Returns: a generated list of column values
+func (c *MySqlPersistence[T]) GenerateColumnsAndValues(objMap map[string]any) ([]string, []any) {
+	if len(objMap) == 0 {
+		return nil, nil
+	}
+
+	ln := len(objMap)
+	columns := make([]string, 0, ln)
+	values := make([]any, 0, ln)
+	for _col := range objMap {
+		columns = append(columns, _col)
+	}
+	if c.preserveColumnOrder {
+		c.sortColumns(columns)
+	}
+	for _, _col := range columns {
+		values = append(values, c.bindValue(_col, objMap[_col]))
+	}
+	return columns, values
+}
+
+// bindValue decodes encoded strings written to binary columns back to bytes
+// and joins arrays written to SET columns when split_set_columns is set.
+// Values of other columns are returned as is. Strings that can't be decoded are rejected
+// by checkBinaryValues before they are bound.
+func (c *MySqlPersistence[T]) bindValue(column string, value any) any {
+	if members, ok := value.([]any); ok && c.splitSetColumns && c.columnType(column) == "set" {
+		return joinSetValue(members)
+	}
+	str, ok := value.(string)
+	if !ok || c.binaryEncoding == BinaryEncodingNone || !isBinaryDataType(c.columnType(column)) {
+		return value
+	}
+	if data, err := c.decodeBinary(str); err == nil {
+		return data
+	}
+	return value
+}
+
+// joinSetValue joins members of a SET value into a comma-separated value.
+func joinSetValue(members []any) string {
+	buf := strings.Builder{}
+	for i, member := range members {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(cconv.StringConverter.ToString(member))
+	}
+	return buf.String()
+}
+
+// sortColumns orders columns by positions of fields of T.
+// Columns without fields and columns of maps are placed after them in alphabetical order.
+func (c *MySqlPersistence[T]) sortColumns(columns []string) {
+	sort.SliceStable(columns, func(i, j int) bool {
+		pi, iok := c.publicFields[strings.ToLower(columns[i])]
+		pj, jok := c.publicFields[strings.ToLower(columns[j])]
+		if iok && jok && pi != pj {
+			return pi < pj
+		}
+		if iok != jok {
+			return iok
+		}
+		return columns[i] < columns[j]
+	})
+}
+
+// GetPageByFilter gets a page of data items retrieved by a given filter and sorted according to sort parameters.
+// This method shall be called by a func (c * MySqlPersistence) getPageByFilter method from child class that
+// receives FilterParams and converts them into a filter function.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object
+//		- paging            (optional) paging parameters
+//		- sort              (optional) sorting JSON object
+//		- select            (optional) projection JSON object
+//		- args              (optional) values of parameters used in the filter
+//	Returns: receives a data page or error.
+func (c *MySqlPersistence[T]) GetPageByFilter(ctx context.Context, correlationId string,
+	filter string, paging cdata.PagingParams, sort string, selection string, args ...any) (page cdata.DataPage[T], err error) {
+	return c.getPageByFilter(ctx, correlationId, false, filter, paging, sort, selection, args...)
+}
+
+// GetDistinctPageByFilter gets a page of distinct data items retrieved by a given filter
+// and sorted according to sort parameters. Duplicate rows are removed with SELECT DISTINCT
+// and the total number of items counts distinct rows as well.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object
+//		- paging            (optional) paging parameters
+//		- sort              (optional) sorting JSON object
+//		- select            (optional) projection JSON object, all columns when empty
+//		- args              (optional) values of parameters used in the filter
+//	Returns: receives a data page or error.
+func (c *MySqlPersistence[T]) GetDistinctPageByFilter(ctx context.Context, correlationId string,
+	filter string, paging cdata.PagingParams, sort string, selection string, args ...any) (page cdata.DataPage[T], err error) {
+	return c.getPageByFilter(ctx, correlationId, true, filter, paging, sort, selection, args...)
+}
+
+func (c *MySqlPersistence[T]) getPageByFilter(ctx context.Context, correlationId string, distinct bool,
+	filter string, paging cdata.PagingParams, sort string, selection string, args ...any) (page cdata.DataPage[T], err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return *cdata.NewEmptyDataPage[T](), err
+	}
+
+	// The count of the total uses the same condition and parameters as the page
+	where := generateWhere(filter)
+	query := "SELECT " + c.generateSelection(distinct, selection) + " FROM " + c.QuotedTableName() + where
+
+	// Adjust max item count based on configuration paging
+	skip, take := c.GetEffectivePaging(paging)
+	pagingEnabled := paging.Total
+
+	if len(sort) > 0 {
+		query += " ORDER BY " + sort
+	}
+
+	query += " LIMIT " + strconv.FormatInt(take, 10)
+
+	if skip > 0 {
+		query += " OFFSET " + strconv.FormatInt(skip, 10)
+	}
+
+	rows, err := c.query(ctx, correlationId, "get_page", query, args...)
+	if err != nil {
+		return *cdata.NewEmptyDataPage[T](), err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		if c.IsTerminated() {
+			rows.Close()
+			return *cdata.NewEmptyDataPage[T](), cerr.
+				NewError("query terminated").
+				WithCorrelationId(correlationId)
+		}
+		item, convErr := c.convertToPublic(correlationId, rows)
+		if convErr != nil {
+			return page, c.wrapError(ctx, correlationId, "get_page", convErr)
+		}
+		items = append(items, item)
+	}
+
+	// The page may be incomplete, so the total is not counted for it
+	if err := rows.Err(); err != nil {
+		return *cdata.NewEmptyDataPage[T](), c.wrapError(ctx, correlationId, "get_page", err)
+	}
+
+	if items != nil {
+		c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
+	}
+
+	if pagingEnabled {
+		var count int64
+		if distinct {
+			count, err = c.getDistinctCount(ctx, correlationId, where, selection, args...)
+		} else {
+			count, err = c.getCount(ctx, correlationId, where, args...)
 		}
-		result.Close()
+		if err != nil {
+			return *cdata.NewEmptyDataPage[T](), err
+		}
+
+		return *cdata.NewDataPage[T](items, int(count)), nil
 	}
-	return nil
+
+	return *cdata.NewDataPage[T](items, cdata.EmptyTotalValue), nil
 }
 
-func (c *MySqlPersistence[T]) checkTableExists(ctx context.Context) (bool, error) {
-	// Check if table exist to determine either to auto create objects
-	query := "SHOW TABLES LIKE '" + c.TableName + "'"
-	result, err := c.Client.QueryContext(ctx, query)
+// GetMapPageByFilter gets a page of data items retrieved by a given filter as maps of column values,
+// e.g. for ad-hoc reports with selections that don't match the data type.
+// NULL values are returned as nil, values of numeric columns as json.Number,
+// values of binary and spatial columns as strings in the binary_encoding and other values as strings.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- filter           (optional) a filter JSON object
+//		- paging           (optional) paging parameters
+//		- sort             (optional) sorting JSON object
+//		- selection        (optional) projection JSON object
+//		- args             (optional) values of parameters used in the filter
+//	Returns: data page or error.
+func (c *MySqlPersistence[T]) GetMapPageByFilter(ctx context.Context, correlationId string,
+	filter string, paging cdata.PagingParams, sort string, selection string, args ...any) (page cdata.DataPage[map[string]any], err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return *cdata.NewEmptyDataPage[map[string]any](), err
+	}
+
+	where := generateWhere(filter)
+	query := "SELECT " + c.generateSelection(false, selection) + " FROM " + c.QuotedTableName() + where
+
+	// Adjust max item count based on configuration paging
+	skip, take := c.GetEffectivePaging(paging)
+
+	if len(sort) > 0 {
+		query += " ORDER BY " + sort
+	}
+
+	query += " LIMIT " + strconv.FormatInt(take, 10)
+
+	if skip > 0 {
+		query += " OFFSET " + strconv.FormatInt(skip, 10)
+	}
+
+	rows, err := c.query(ctx, correlationId, "get_page", query, args...)
 	if err != nil {
-		return false, err
+		return *cdata.NewEmptyDataPage[map[string]any](), err
 	}
+	defer rows.Close()
 
-	defer result.Close()
+	items := make([]map[string]any, 0)
+	for rows.Next() {
+		if c.IsTerminated() {
+			rows.Close()
+			return *cdata.NewEmptyDataPage[map[string]any](), cerr.
+				NewError("query terminated").
+				WithCorrelationId(correlationId)
+		}
+		item, convErr := c.convertToMap(rows)
+		if convErr != nil {
+			return page, convErr
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return *cdata.NewEmptyDataPage[map[string]any](), err
+	}
+
+	c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
 
-	columns, err := result.Columns()
+	if paging.Total {
+		count, err := c.getCount(ctx, correlationId, where, args...)
+		if err != nil {
+			return *cdata.NewEmptyDataPage[map[string]any](), err
+		}
+		return *cdata.NewDataPage[map[string]any](items, int(count)), nil
+	}
+
+	return *cdata.NewDataPage[map[string]any](items, cdata.EmptyTotalValue), nil
+}
+
+// convertToMap reads the current row as a map of column values without conversion to the data type.
+func (c *MySqlPersistence[T]) convertToMap(rows *sql.Rows) (map[string]any, error) {
+	columns, err := rows.Columns()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
 	}
-	// Make a slice for the values
-	values := make([]sql.RawBytes, len(columns))
 
-	// rows.Scan wants '[]interface{}' as an argument, so we must copy the
-	// references into such a slice
-	// See http://code.google.com/p/go-wiki/wiki/InterfaceSlice for details
-	scanArgs := make([]interface{}, len(values))
+	values := make([]sql.RawBytes, len(columns))
+	scanArgs := make([]any, len(values))
 	for i := range values {
 		scanArgs[i] = &values[i]
 	}
+	if err = rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
 
-	// If table already exists then exit
-	if result.Next() {
-
-		// get RawBytes from data
-		err = result.Scan(scanArgs...)
-		if err != nil {
-			return false, err
+	item := make(map[string]any, len(columns))
+	for i, column := range columns {
+		switch {
+		case values[i] == nil:
+			item[column] = nil
+		case c.binaryEncoding != BinaryEncodingNone && isBinaryColumn(columnTypes[i]):
+			item[column] = c.encodeBinary(values[i])
+		case c.splitSetColumns && isSetColumn(columnTypes[i]):
+			item[column] = splitSetValue(values[i])
+		case isNumericColumn(columnTypes[i]):
+			item[column] = json.Number(values[i])
+		default:
+			item[column] = string(values[i])
 		}
+	}
+	return item, nil
+}
 
-		var table string
-		for _, col := range values {
-			// Here we can check if the value is nil (NULL value)
-			if col == nil {
-				table = "NULL"
-			} else {
-				table = string(col)
-			}
+// GetDistinctValues gets distinct values of a column in items that match to a given filter,
+// e.g. to fill filter options. Values are converted like in GetMapPageByFilter.
+//
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- column           a column to get values of
+//		- filter           (optional) a filter JSON object
+//		- args             (optional) values of parameters used in the filter
+//	Returns: distinct values or error.
+func (c *MySqlPersistence[T]) GetDistinctValues(ctx context.Context, correlationId string,
+	column string, filter string, args ...any) ([]any, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
 
-			if table == c.TableName {
-				return true, nil
-			}
+	query := "SELECT DISTINCT " + c.QuoteIdentifier(column) + " FROM " + c.QuotedTableName()
+	if len(filter) > 0 {
+		query += " WHERE " + filter
+	}
+
+	rows, err := c.query(ctx, correlationId, "get_distinct_values", query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]any, 0)
+	for rows.Next() {
+		item, convErr := c.convertToMap(rows)
+		if convErr != nil {
+			return values, convErr
 		}
+		for _, value := range item {
+			values = append(values, value)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	c.Logger.Trace(ctx, correlationId, "Retrieved %d distinct values of %s from %s", len(values), column, c.TableName)
+	return values, nil
+}
+
+// ExecuteQuery executes a custom statement with @name parameters, e.g. for bulk updates.
+// Parameters are replaced with positional placeholders by ReplaceNamedParameters.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- query            a statement with @name parameters
+//		- params           values of the parameters keyed by their names without @
+//	Returns: result of the statement or error.
+func (c *MySqlPersistence[T]) ExecuteQuery(ctx context.Context, correlationId string,
+	query string, params map[string]any) (sql.Result, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
 
+	query, args, err := ReplaceNamedParameters(correlationId, query, params)
+	if err != nil {
+		return nil, err
 	}
-	return false, nil
+	return c.exec(ctx, correlationId, "execute_query", query, args...)
 }
 
-// GenerateColumns generates a list of column names to use in SQL statements like: "column1,column2,column3"
+// Query executes a custom query which returns rows, e.g. in methods of derived persistences.
+// The operation timeout is not applied, since it would cancel reading of the returned rows.
 //	Parameters:
-//		- columns an array with column values
-//	Returns: a generated list of column names
-func (c *MySqlPersistence[T]) GenerateColumns(columns []string) string {
-	if len(columns) == 0 {
-		return ""
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- query            a statement with positional ? parameters
+//		- args             values of the parameters
+//	Returns: rows which must be closed by the caller or error.
+func (c *MySqlPersistence[T]) Query(ctx context.Context, correlationId string,
+	query string, args ...any) (*sql.Rows, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx = ContextWithCorrelationId(ctx, correlationId)
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
 	}
+	return c.query(ctx, correlationId, "query", query, args...)
+}
 
-	builder := strings.Builder{}
-	for _, item := range columns {
-		if builder.String() != "" {
-			builder.WriteString(",")
+// GetEffectivePaging gets the window of items actually applied by GetPageByFilter,
+// where the number of items to take is the default page size when it is not set, i.e. zero or negative,
+// and is capped by the max page size.
+//	Parameters:
+//		- paging paging parameters
+//	Returns: number of items to skip and number of items to take.
+func (c *MySqlPersistence[T]) GetEffectivePaging(paging cdata.PagingParams) (skip int64, take int64) {
+	return paging.GetSkip(0), c.effectiveTake(paging.Take)
+}
+
+// effectiveTake gets the number of items in a page: the default page size or the max page size
+// when the requested number is not set, capped by the max page size.
+func (c *MySqlPersistence[T]) effectiveTake(take int64) int64 {
+	if take <= 0 {
+		take = int64(c.DefaultPageSize)
+	}
+	if take <= 0 || take > int64(c.MaxPageSize) {
+		take = int64(c.MaxPageSize)
+	}
+	return take
+}
+
+// GetCountByFilter gets a number of data items retrieved by a given filter.
+// This method shall be called by a func (c * MySqlPersistence) getCountByFilter method from child class that
+// receives FilterParams and converts them into a filter function.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object
+//		- args              (optional) values of parameters used in the filter
+//	Returns: data page or error.
+func (c *MySqlPersistence[T]) GetCountByFilter(ctx context.Context, correlationId string,
+	filter string, args ...any) (int64, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return 0, err
+	}
+
+	return c.getCount(ctx, correlationId, generateWhere(filter), args...)
+}
+
+// getCount counts items that match the WHERE clause generated by generateWhere.
+func (c *MySqlPersistence[T]) getCount(ctx context.Context, correlationId string,
+	where string, args ...any) (int64, error) {
+
+	query := "SELECT COUNT(*) AS count FROM " + c.QuotedTableName() + where
+
+	rows, err := c.query(ctx, correlationId, "get_count", query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			return 0, c.wrapError(ctx, correlationId, "get_count", err)
 		}
-		builder.WriteString(c.QuoteIdentifier(item))
 	}
-	return builder.String()
 
+	if count != 0 {
+		c.Logger.Trace(ctx, correlationId, "Counted %d items in %s", count, c.TableName)
+	}
+
+	return count, c.wrapError(ctx, correlationId, "get_count", rows.Err())
 }
 
-// GenerateParameters generates a list of value parameters to use in SQL statements like: "?,?,?"
+// CountAll gets a number of all data items in the table.
+// With the approximate_count option the number is taken from the table statistics,
+// which is fast on large tables but may differ from the exact number for InnoDB tables.
 //	Parameters:
-//		- values an array with column values or a key-value map
-//	Returns: a generated list of value parameters
-func (c *MySqlPersistence[T]) GenerateParameters(valuesCount int) string {
-	if valuesCount <= 0 {
-		return ""
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//	Returns: number of items or error.
+func (c *MySqlPersistence[T]) CountAll(ctx context.Context, correlationId string) (int64, error) {
+	if !c.approximateCount {
+		return c.GetCountByFilter(ctx, correlationId, "")
 	}
 
-	builder := strings.Builder{}
-	for index := 1; index <= valuesCount; index++ {
-		if builder.String() != "" {
-			builder.WriteString(",")
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	queryCtx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return 0, err
+	}
+
+	condition, args := c.tableMetadataCondition()
+	query := "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE " + condition
+
+	rows, err := c.query(queryCtx, correlationId, "count_all", query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count sql.NullInt64
+	if rows.Next() {
+		if err = rows.Scan(&count); err != nil {
+			return 0, err
 		}
-		builder.WriteString("?")
 	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
 
-	return builder.String()
+	// Views have no statistics, so their rows are counted
+	if !count.Valid {
+		return c.GetCountByFilter(ctx, correlationId, "")
+	}
+
+	c.Logger.Trace(queryCtx, correlationId, "Estimated %d items in %s", count.Int64, c.TableName)
+	return count.Int64, nil
 }
 
-// GenerateSetParameters generates a list of column sets to use in UPDATE statements like: column1=?,column2=?
-//	Parameters:
-//		- values an array with column values or a key-value map
-//	Returns: a generated list of column sets
-func (c *MySqlPersistence[T]) GenerateSetParameters(columns []string) string {
+// getDistinctCount counts distinct rows of the selection in a subquery,
+// so the count is consistent with rows returned by SELECT DISTINCT.
+func (c *MySqlPersistence[T]) getDistinctCount(ctx context.Context, correlationId string,
+	where string, selection string, args ...any) (int64, error) {
 
-	if len(columns) == 0 {
-		return ""
+	query := "SELECT " + c.generateSelection(true, selection) + " FROM " + c.QuotedTableName() + where
+	query = "SELECT COUNT(*) AS count FROM (" + query + ") AS distinct_rows"
+
+	rows, err := c.query(ctx, correlationId, "get_count", query, args...)
+	if err != nil {
+		return 0, err
 	}
-	setParamsBuf := strings.Builder{}
-	index := 1
-	for i := range columns {
-		if setParamsBuf.String() != "" {
-			setParamsBuf.WriteString(",")
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			return 0, err
 		}
-		setParamsBuf.WriteString(c.QuoteIdentifier(columns[i]) + "=?")
-		index++
 	}
-	return setParamsBuf.String()
+	return count, rows.Err()
 }
 
-// GenerateColumnsAndValues generates a list of column parameters
-//	Parameters:
-//		- values an array with column values or a key-value map
-//	Returns: a generated list of column values
-func (c *MySqlPersistence[T]) GenerateColumnsAndValues(objMap map[string]any) ([]string, []any) {
-	if len(objMap) == 0 {
-		return nil, nil
+// generateWhere generates the WHERE clause of the filter, empty when there is no filter.
+func generateWhere(filter string) string {
+	if len(filter) == 0 {
+		return ""
 	}
+	return " WHERE " + filter
+}
 
-	ln := len(objMap)
-	columns := make([]string, 0, ln)
-	values := make([]any, 0, ln)
-	for _col, _val := range objMap {
-		columns = append(columns, _col)
-		values = append(values, _val)
+// generateSelection generates a list of selected columns, all columns when the selection is empty.
+// Repeated columns are removed, since they fail in derived tables with a duplicate column name error.
+func (c *MySqlPersistence[T]) generateSelection(distinct bool, selection string) string {
+	if len(selection) == 0 {
+		selection = "*"
+	} else {
+		selection = deduplicateColumns(selection, c.identifierQuoteChar())
 	}
-	return columns, values
+	if distinct {
+		return "DISTINCT " + selection
+	}
+	return selection
+}
+
+// deduplicateColumns removes repeated columns from the selection keeping the order of the first ones.
+// Columns are compared without backticks or the given identifier quotes and case, expressions are compared as is.
+func deduplicateColumns(selection string, identifierQuote string) string {
+	columns := make([]string, 0)
+	depth, start := 0, 0
+	var quote rune
+	for i, ch := range selection {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			columns = append(columns, strings.TrimSpace(selection[start:i]))
+			start = i + 1
+		}
+	}
+	columns = append(columns, strings.TrimSpace(selection[start:]))
+
+	seen := make(map[string]bool, len(columns))
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		key := strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(column, "`", ""), identifierQuote, ""))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, column)
+	}
+	if len(result) == len(columns) {
+		return selection
+	}
+	return strings.Join(result, ", ")
+}
+
+// GetListByFilter gets a list of data items retrieved by a given filter and sorted according to sort parameters.
+// This method shall be called by a func (c * MySqlPersistence) getListByFilter method from child class that
+// receives FilterParams and converts them into a filter function.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- filter           (optional) a filter JSON object
+//		- paging           (optional) paging parameters
+//		- sort             (optional) sorting JSON object
+//		- select           (optional) projection JSON object
+//		- args             (optional) values of parameters used in the filter
+//	Returns: data list or error.
+func (c *MySqlPersistence[T]) GetListByFilter(ctx context.Context, correlationId string,
+	filter string, sort string, selection string, args ...any) (items []T, err error) {
+	return c.getListByFilter(ctx, correlationId, false, filter, sort, selection, args...)
 }
 
-// GetPageByFilter gets a page of data items retrieved by a given filter and sorted according to sort parameters.
-// This method shall be called by a func (c * MySqlPersistence) getPageByFilter method from child class that
-// receives FilterParams and converts them into a filter function.
+// GetDistinctListByFilter gets a list of distinct data items retrieved by a given filter
+// and sorted according to sort parameters. Duplicate rows are removed with SELECT DISTINCT.
 //	Parameters:
 //		- ctx context.Context
-//		- correlationId     (optional) transaction id to trace execution through call chain.
-//		- filter            (optional) a filter JSON object
-//		- paging            (optional) paging parameters
-//		- sort              (optional) sorting JSON object
-//		- select            (optional) projection JSON object
-//	Returns: receives a data page or error.
-func (c *MySqlPersistence[T]) GetPageByFilter(ctx context.Context, correlationId string,
-	filter string, paging cdata.PagingParams, sort string, selection string) (page cdata.DataPage[T], err error) {
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//		- filter           (optional) a filter JSON object
+//		- sort             (optional) sorting JSON object
+//		- select           (optional) projection JSON object, all columns when empty
+//		- args             (optional) values of parameters used in the filter
+//	Returns: data list or error.
+func (c *MySqlPersistence[T]) GetDistinctListByFilter(ctx context.Context, correlationId string,
+	filter string, sort string, selection string, args ...any) (items []T, err error) {
+	return c.getListByFilter(ctx, correlationId, true, filter, sort, selection, args...)
+}
 
-	query := "SELECT * FROM " + c.QuotedTableName()
-	if len(selection) > 0 {
-		query = "SELECT " + selection + " FROM " + c.QuotedTableName()
+func (c *MySqlPersistence[T]) getListByFilter(ctx context.Context, correlationId string, distinct bool,
+	filter string, sort string, selection string, args ...any) (items []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
 	}
 
-	// Adjust max item count based on configuration paging
-	skip := paging.GetSkip(-1)
-	take := paging.GetTake((int64)(c.MaxPageSize))
-	pagingEnabled := paging.Total
+	query := "SELECT " + c.generateSelection(distinct, selection) + " FROM " + c.QuotedTableName()
 
 	if len(filter) > 0 {
 		query += " WHERE " + filter
 	}
+
 	if len(sort) > 0 {
 		query += " ORDER BY " + sort
 	}
 
-	query += " LIMIT " + strconv.FormatInt(take, 10)
-
-	if skip >= 0 {
-		query += " OFFSET " + strconv.FormatInt(skip, 10)
-	}
-
-	rows, err := c.Client.QueryContext(ctx, query)
+	rows, err := c.query(ctx, correlationId, "get_list", query, args...)
 	if err != nil {
-		return *cdata.NewEmptyDataPage[T](), err
+		return nil, err
 	}
 	defer rows.Close()
 
-	items := make([]T, 0)
+	items = make([]T, 0, 1)
 	for rows.Next() {
 		if c.IsTerminated() {
 			rows.Close()
-			return *cdata.NewEmptyDataPage[T](), cerr.
+			return nil, cerr.
 				NewError("query terminated").
 				WithCorrelationId(correlationId)
 		}
-		item, convErr := c.Overrides.ConvertToPublic(rows)
+		item, convErr := c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return page, convErr
+			return items, c.wrapError(ctx, correlationId, "get_list", convErr)
 		}
 		items = append(items, item)
 	}
@@ -784,117 +3562,175 @@ func (c *MySqlPersistence[T]) GetPageByFilter(ctx context.Context, correlationId
 		c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
 	}
 
-	if pagingEnabled {
-		count, err := c.GetCountByFilter(ctx, correlationId, filter)
-		if err != nil {
-			return *cdata.NewEmptyDataPage[T](), err
-		}
-
-		return *cdata.NewDataPage[T](items, int(count)), nil
-	}
-
-	return *cdata.NewDataPage[T](items, cdata.EmptyTotalValue), rows.Err()
+	return items, c.wrapError(ctx, correlationId, "get_list", rows.Err())
 }
 
-// GetCountByFilter gets a number of data items retrieved by a given filter.
-// This method shall be called by a func (c * MySqlPersistence) getCountByFilter method from child class that
-// receives FilterParams and converts them into a filter function.
+// GetAll gets all data items stored in the table, e.g. to export them.
+// The items are read in a single query, so a warning is logged when their number
+// exceeds the get_all_warn_size option. Use GetPageByCursor to read large tables in parts.
 //	Parameters:
 //		- ctx context.Context
-//		- correlationId     (optional) transaction id to trace execution through call chain.
-//		- filter            (optional) a filter JSON object
-//	Returns: data page or error.
-func (c *MySqlPersistence[T]) GetCountByFilter(ctx context.Context, correlationId string,
-	filter string) (int64, error) {
-
-	query := "SELECT COUNT(*) AS count FROM " + c.QuotedTableName()
-	if len(filter) > 0 {
-		query += " WHERE " + filter
+//		- correlationId    (optional) transaction id to trace execution through call chain.
+//	Returns: data list or error.
+func (c *MySqlPersistence[T]) GetAll(ctx context.Context, correlationId string) (items []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
 	}
 
-	rows, err := c.Client.QueryContext(ctx, query)
+	query := "SELECT * FROM " + c.QuotedTableName()
+
+	rows, err := c.query(ctx, correlationId, "get_all", query)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var count int64
-	values := make([]sql.RawBytes, 1)
-	scanArgs := make([]interface{}, 1)
-	for i := range values {
-		scanArgs[i] = &values[i]
-	}
-
-	if rows.Next() {
-		err = rows.Scan(scanArgs...)
-		if err != nil {
-			return 0, err
+	items = make([]T, 0)
+	for rows.Next() {
+		if c.IsTerminated() {
+			return nil, cerr.
+				NewError("query terminated").
+				WithCorrelationId(correlationId)
+		}
+		if ctx.Err() != nil {
+			return nil, c.wrapError(ctx, correlationId, "get_all", ctx.Err())
+		}
+		item, convErr := c.convertToPublic(correlationId, rows)
+		if convErr != nil {
+			return items, convErr
+		}
+		items = append(items, item)
+		// The warning is logged once, when the number of items exceeds the limit
+		if c.getAllWarnSize > 0 && len(items) == c.getAllWarnSize+1 {
+			c.Logger.Warn(ctx, correlationId, "Reading more than %d items from %s, consider paging", c.getAllWarnSize, c.TableName)
 		}
-
-		count = cconv.LongConverter.ToLong(string(values[0]))
 	}
-
-	if count != 0 {
-		c.Logger.Trace(ctx, correlationId, "Counted %d items in %s", count, c.TableName)
+	if err = rows.Err(); err != nil {
+		return nil, c.wrapError(ctx, correlationId, "get_all", err)
 	}
 
-	return count, rows.Err()
+	c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
+	return items, nil
 }
 
-// GetListByFilter gets a list of data items retrieved by a given filter and sorted according to sort parameters.
-// This method shall be called by a func (c * MySqlPersistence) getListByFilter method from child class that
-// receives FilterParams and converts them into a filter function.
+// GetPageByCursor gets a page of data items that follow a cursor value in the order of a sort column.
+// Unlike offset paging, it doesn't skip or duplicate items when data is changed between the pages
+// and works fast on deep pages when the sort column is indexed. The sort column must have unique values.
+// Cursor values are taken from the sort column of the table, which may be missing in data items,
+// e.g. a generated column.
 //	Parameters:
 //		- ctx context.Context
-//		- correlationId    (optional) transaction id to trace execution through call chain.
-//		- filter           (optional) a filter JSON object
-//		- paging           (optional) paging parameters
-//		- sort             (optional) sorting JSON object
-//		- select           (optional) projection JSON object
-//	Returns: data list or error.
-func (c *MySqlPersistence[T]) GetListByFilter(ctx context.Context, correlationId string,
-	filter string, sort string, selection string) (items []T, err error) {
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object
+//		- sortColumn        a column to sort items and take cursor values from
+//		- afterValue        (optional) a cursor value returned with the previous page, nil to get the first page
+//		- limit             a maximum number of items in the page, capped by the max page size, 0 to use the default page size
+//		- args              (optional) values of parameters used in the filter
+//	Returns: data items, a cursor value for the next page or nil when there are no more items, or error.
+func (c *MySqlPersistence[T]) GetPageByCursor(ctx context.Context, correlationId string,
+	filter string, sortColumn string, afterValue any, limit int, args ...any) (items []T, next any, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, nil, err
+	}
 
-	query := "SELECT * FROM " + c.QuotedTableName()
+	limit = int(c.effectiveTake(int64(limit)))
 
-	if len(selection) > 0 {
-		query = "SELECT " + selection + " FROM " + c.QuotedTableName()
-	}
+	column := c.QuoteIdentifier(sortColumn)
+	query := "SELECT * FROM " + c.QuotedTableName()
 
+	conditions := make([]string, 0, 2)
+	params := make([]any, 0, len(args)+2)
 	if len(filter) > 0 {
-		query += " WHERE " + filter
+		conditions = append(conditions, "("+filter+")")
+		params = append(params, args...)
 	}
-
-	if len(sort) > 0 {
-		query += " ORDER BY " + sort
+	if afterValue != nil {
+		conditions = append(conditions, column+">?")
+		params = append(params, afterValue)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
+	query += " ORDER BY " + column + " LIMIT ?"
+	params = append(params, limit)
 
-	rows, err := c.Client.QueryContext(ctx, query)
+	rows, err := c.query(ctx, correlationId, "get_page_by_cursor", query, params...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	items = make([]T, 0, 1)
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, c.wrapError(ctx, correlationId, "get_page_by_cursor", err)
+	}
+	cursorIndex := -1
+	for i, name := range columns {
+		// Column names are case-insensitive
+		if strings.EqualFold(name, sortColumn) {
+			cursorIndex = i
+		}
+	}
+	if cursorIndex < 0 {
+		return nil, nil, cerr.NewBadRequestError(correlationId, "UNKNOWN_SORT_COLUMN",
+			"Sort column "+sortColumn+" is not found in "+c.TableName).
+			WithDetails("column", sortColumn)
+	}
+
+	items = make([]T, 0)
 	for rows.Next() {
 		if c.IsTerminated() {
 			rows.Close()
-			return nil, cerr.
+			return nil, nil, cerr.
 				NewError("query terminated").
 				WithCorrelationId(correlationId)
 		}
-		item, convErr := c.Overrides.ConvertToPublic(rows)
+		// A full page may be followed by more items, so the cursor is read from its last row
+		if len(items) == limit-1 {
+			next, err = scanColumn(rows, len(columns), cursorIndex)
+			if err != nil {
+				return items, nil, c.wrapError(ctx, correlationId, "get_page_by_cursor", err)
+			}
+		}
+		item, convErr := c.convertToPublic(correlationId, rows)
 		if convErr != nil {
-			return items, convErr
+			return items, nil, c.wrapError(ctx, correlationId, "get_page_by_cursor", convErr)
 		}
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return items, nil, c.wrapError(ctx, correlationId, "get_page_by_cursor", err)
+	}
 
-	if items != nil {
-		c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
+	c.Logger.Trace(ctx, correlationId, "Retrieved %d from %s", len(items), c.TableName)
+
+	if len(items) < limit {
+		return items, nil, nil
 	}
+	return items, next, nil
+}
 
-	return items, rows.Err()
+// scanColumn reads a value of the column from the current row. The row can be read again after that.
+// Text values are returned as strings, so they are compared with the column collation.
+func scanColumn(rows *sql.Rows, count int, index int) (any, error) {
+	values := make([]any, count)
+	scanArgs := make([]any, count)
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
+	if buf, ok := values[index].([]byte); ok {
+		return string(buf), nil
+	}
+	return values[index], nil
 }
 
 // GetOneRandom gets a random item from items that match to a given filter.
@@ -904,9 +3740,16 @@ func (c *MySqlPersistence[T]) GetListByFilter(ctx context.Context, correlationId
 //		- ctx context.Context
 //		- correlationId     (optional) transaction id to trace execution through call chain.
 //		- filter            (optional) a filter JSON object
+//		- args              (optional) values of parameters used in the filter
 //	Returns: random item or error.
-func (c *MySqlPersistence[T]) GetOneRandom(ctx context.Context, correlationId string, filter string) (item T, err error) {
-	count, err := c.GetCountByFilter(ctx, correlationId, filter)
+func (c *MySqlPersistence[T]) GetOneRandom(ctx context.Context, correlationId string, filter string, args ...any) (item T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return item, err
+	}
+	count, err := c.GetCountByFilter(ctx, correlationId, filter, args...)
 	if err != nil {
 		return item, err
 	}
@@ -930,7 +3773,7 @@ func (c *MySqlPersistence[T]) GetOneRandom(ctx context.Context, correlationId st
 	}
 	query += " LIMIT 1" + " OFFSET " + strconv.FormatInt(pos, 10)
 
-	rows, err := c.Client.QueryContext(ctx, query)
+	rows, err := c.query(ctx, correlationId, "get_one_random", query, args...)
 	if err != nil {
 		return item, err
 	}
@@ -938,18 +3781,64 @@ func (c *MySqlPersistence[T]) GetOneRandom(ctx context.Context, correlationId st
 
 	if !rows.Next() {
 		c.Logger.Trace(ctx, correlationId, "Random item wasn't found from %s", c.TableName)
-		return item, rows.Err()
+		return item, c.wrapError(ctx, correlationId, "get_one_random", rows.Err())
 	}
 
-	item, convErr := c.Overrides.ConvertToPublic(rows)
+	item, convErr := c.convertToPublic(correlationId, rows)
 	if convErr != nil {
-		return item, convErr
+		return item, c.wrapError(ctx, correlationId, "get_one_random", convErr)
 	}
 	c.Logger.Trace(ctx, correlationId, "Retrieved random item from %s", c.TableName)
 	return item, nil
 
 }
 
+// GetFirstByFilter gets the first item from items that match to a given filter
+// in the order of sort parameters, e.g. the latest item when sorted by creation time descending.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object
+//		- sort              (optional) sorting JSON object
+//		- args              (optional) values of parameters used in the filter
+//	Returns: the first item, true when it was found and false otherwise, or error.
+func (c *MySqlPersistence[T]) GetFirstByFilter(ctx context.Context, correlationId string,
+	filter string, sort string, args ...any) (item T, ok bool, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return item, false, err
+	}
+
+	query := "SELECT * FROM " + c.QuotedTableName()
+	if len(filter) > 0 {
+		query += " WHERE " + filter
+	}
+	if len(sort) > 0 {
+		query += " ORDER BY " + sort
+	}
+	query += " LIMIT 1"
+
+	rows, err := c.query(ctx, correlationId, "get_first", query, args...)
+	if err != nil {
+		return item, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		c.Logger.Trace(ctx, correlationId, "Nothing found from %s", c.TableName)
+		return item, false, c.wrapError(ctx, correlationId, "get_first", rows.Err())
+	}
+
+	item, convErr := c.convertToPublic(correlationId, rows)
+	if convErr != nil {
+		return item, false, c.wrapError(ctx, correlationId, "get_first", convErr)
+	}
+	c.Logger.Trace(ctx, correlationId, "Retrieved first item from %s", c.TableName)
+	return item, true, nil
+}
+
 // Create creates a data item.
 //	Parameters:
 //		- ctx context.Context
@@ -957,19 +3846,32 @@ func (c *MySqlPersistence[T]) GetOneRandom(ctx context.Context, correlationId st
 //		- item              an item to be created.
 //	Returns: (optional) callback function that receives created item or error.
 func (c *MySqlPersistence[T]) Create(ctx context.Context, correlationId string, item T) (result T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return result, err
+	}
 	objMap, convErr := c.Overrides.ConvertFromPublic(item)
 	if convErr != nil {
 		return result, convErr
 	}
 
+	if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+		return result, err
+	}
+	if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+		return result, err
+	}
 	columns, values := c.GenerateColumnsAndValues(objMap)
 
 	columnsStr := c.GenerateColumns(columns)
 	paramsStr := c.GenerateParameters(len(values))
 
 	query := "INSERT INTO " + c.QuotedTableName() + " (" + columnsStr + ") VALUES (" + paramsStr + ")"
+	c.traceParams(ctx, correlationId, columns, values)
 
-	rows, err := c.Client.QueryContext(ctx, query, values...)
+	rows, err := c.query(ctx, correlationId, "create", query, values...)
 	if err != nil {
 		return result, err
 	}
@@ -980,6 +3882,144 @@ func (c *MySqlPersistence[T]) Create(ctx context.Context, correlationId string,
 	return item, nil
 }
 
+// CreateBatch creates data items with multi-row INSERT statements.
+// Items are split into statements that fit the max_allowed_packet of the server or options.max_packet_size.
+// The statements are not executed in a transaction, so items inserted before an error stay created.
+//	Parameters:
+//		- ctx context.Context
+//		- correlation_id    (optional) transaction id to trace execution through call chain.
+//		- items             items to be created.
+//	Returns: created items or error.
+func (c *MySqlPersistence[T]) CreateBatch(ctx context.Context, correlationId string, items []T) (result []T, err error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return nil, err
+	}
+	objMaps, err := c.convertBatch(ctx, correlationId, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.insertBatch(ctx, correlationId, "create_batch", objMaps, false); err != nil {
+		return nil, err
+	}
+	c.Logger.Trace(ctx, correlationId, "Created %d in %s", len(items), c.TableName)
+	return items, nil
+}
+
+// convertBatch converts items to column values to write them in batch statements.
+func (c *MySqlPersistence[T]) convertBatch(ctx context.Context, correlationId string, items []T) ([]map[string]any, error) {
+	objMaps := make([]map[string]any, len(items))
+	for i, item := range items {
+		objMap, err := c.Overrides.ConvertFromPublic(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkUnknownColumns(ctx, correlationId, objMap); err != nil {
+			return nil, err
+		}
+		if err := c.checkBinaryValues(correlationId, objMap); err != nil {
+			return nil, err
+		}
+		objMaps[i] = objMap
+	}
+	return objMaps, nil
+}
+
+// insertBatch writes rows with multi-row INSERT statements. Consecutive rows with the same columns
+// share a statement until its estimated size reaches the packet size or the number of placeholders reaches its limit.
+// A row that doesn't fit the packet by itself is sent in its own statement to get the error of the server.
+//	Parameters:
+//		- upsert true to update existing rows with the inserted values
+func (c *MySqlPersistence[T]) insertBatch(ctx context.Context, correlationId string, operation string,
+	objMaps []map[string]any, upsert bool) error {
+
+	var columns []string
+	var values []any
+	rowsCount, size := 0, 0
+
+	flush := func() error {
+		if rowsCount == 0 {
+			return nil
+		}
+		query := c.batchInsertQuery(columns, rowsCount, upsert)
+		_, err := c.exec(ctx, correlationId, operation, query, values...)
+		columns, values, rowsCount, size = nil, nil, 0, 0
+		return err
+	}
+
+	for _, objMap := range objMaps {
+		rowColumns, rowValues := c.GenerateColumnsAndValues(objMap)
+		c.traceParams(ctx, correlationId, rowColumns, rowValues)
+
+		rowSize := 2 + 2*len(rowValues)
+		for _, value := range rowValues {
+			rowSize += estimateValueSize(value)
+		}
+
+		if rowsCount > 0 && (!equalColumns(columns, rowColumns) ||
+			c.maxPacketSize > 0 && size+rowSize > c.maxPacketSize ||
+			len(values)+len(rowValues) > maxBatchParameters) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if rowsCount == 0 {
+			columns = rowColumns
+			size = len(c.batchInsertQuery(columns, 0, upsert))
+		}
+		values = append(values, rowValues...)
+		rowsCount++
+		size += rowSize
+	}
+	return flush()
+}
+
+// batchInsertQuery generates an INSERT statement with placeholders of the given number of rows.
+func (c *MySqlPersistence[T]) batchInsertQuery(columns []string, rowsCount int, upsert bool) string {
+	row := "(" + c.GenerateParameters(len(columns)) + ")"
+	rows := make([]string, rowsCount)
+	for i := range rows {
+		rows[i] = row
+	}
+
+	query := "INSERT INTO " + c.QuotedTableName() + " (" + c.GenerateColumns(columns) + ") VALUES " + strings.Join(rows, ",")
+	if upsert {
+		query += " ON DUPLICATE KEY UPDATE " + c.GenerateUpsertParameters(columns)
+	}
+	return query
+}
+
+// equalColumns checks if rows have the same columns in the same order.
+func equalColumns(columns []string, otherColumns []string) bool {
+	if len(columns) != len(otherColumns) {
+		return false
+	}
+	for i := range columns {
+		if columns[i] != otherColumns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateValueSize estimates the number of bytes the value takes in a statement.
+// Strings are counted as escaped by interpolate_params in the worst case.
+func estimateValueSize(value any) int {
+	switch v := value.(type) {
+	case nil:
+		return 4
+	case string:
+		return 2*len(v) + 2
+	case []byte:
+		return 2*len(v) + 3
+	default:
+		return len(fmt.Sprint(v))
+	}
+}
+
 // DeleteByFilter deletes data items that match to a given filter.
 // This method shall be called by a func (c * MySqlPersistence) deleteByFilter method from child class that
 // receives FilterParams and converts them into a filter function.
@@ -987,14 +4027,23 @@ func (c *MySqlPersistence[T]) Create(ctx context.Context, correlationId string,
 //		- ctx context.Context
 //		- correlationId     (optional) transaction id to trace execution through call chain.
 //		- filter            (optional) a filter JSON object.
+//		- args              (optional) values of parameters used in the filter
 //	Returns: error or nil for success.
-func (c *MySqlPersistence[T]) DeleteByFilter(ctx context.Context, correlationId string, filter string) error {
+func (c *MySqlPersistence[T]) DeleteByFilter(ctx context.Context, correlationId string, filter string, args ...any) error {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+	if err := c.checkOpened(correlationId); err != nil {
+		return err
+	}
 	query := "DELETE FROM " + c.QuotedTableName()
 	if len(filter) > 0 {
 		query += " WHERE " + filter
 	}
+	// Ids of the deleted items are not known, so all cached items of the table are removed
+	defer c.removeAllCached(ctx, correlationId)
 
-	result, err := c.Client.ExecContext(ctx, query)
+	result, err := c.execDelete(ctx, correlationId, "delete_by_filter", query, args...)
 	if err != nil {
 		return err
 	}
@@ -1008,11 +4057,86 @@ func (c *MySqlPersistence[T]) DeleteByFilter(ctx context.Context, correlationId
 	return nil
 }
 
+// DeleteByFilterBatched deletes data items that match to a given filter in batches
+// of a limited size, so a large delete doesn't hold locks for a long time.
+// The operation timeout is applied to each batch and canceled context stops deleting between batches.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId     (optional) transaction id to trace execution through call chain.
+//		- filter            (optional) a filter JSON object.
+//		- batchSize         a maximum number of items deleted in one batch
+//		- args              (optional) values of parameters used in the filter
+//	Returns: a total number of deleted items or error.
+func (c *MySqlPersistence[T]) DeleteByFilterBatched(ctx context.Context, correlationId string,
+	filter string, batchSize int, args ...any) (int64, error) {
+	correlationId = c.ResolveCorrelationId(ctx, correlationId)
+	if err := c.checkOpened(correlationId); err != nil {
+		return 0, err
+	}
+	if batchSize <= 0 {
+		return 0, cerr.NewBadRequestError(correlationId, "INVALID_BATCH_SIZE",
+			"Batch size must be positive").
+			WithDetails("batch_size", batchSize)
+	}
+
+	query := "DELETE FROM " + c.QuotedTableName()
+	if len(filter) > 0 {
+		query += " WHERE " + filter
+	}
+	query += " LIMIT " + strconv.Itoa(batchSize)
+	// Ids of the deleted items are not known, so all cached items of the table are removed
+	defer c.removeAllCached(ctx, correlationId)
+
+	var total int64
+	for {
+		if ctx.Err() != nil {
+			return total, c.wrapError(ctx, correlationId, "delete_by_filter", ctx.Err())
+		}
+		if c.IsTerminated() {
+			return total, cerr.
+				NewError("query terminated").
+				WithCorrelationId(correlationId)
+		}
+
+		count, err := c.deleteBatch(ctx, correlationId, query, args...)
+		total += count
+		if err != nil {
+			return total, err
+		}
+		if count < int64(batchSize) {
+			break
+		}
+	}
+
+	c.Logger.Trace(ctx, correlationId, "Deleted %d items from %s", total, c.TableName)
+	return total, nil
+}
+
+// deleteBatch executes a single batch of DeleteByFilterBatched with its own operation timeout.
+func (c *MySqlPersistence[T]) deleteBatch(ctx context.Context, correlationId string,
+	query string, args ...any) (int64, error) {
+	ctx, cancel := c.withOperationContext(ctx, correlationId)
+	defer cancel()
+
+	result, err := c.execDelete(ctx, correlationId, "delete_by_filter", query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (c *MySqlPersistence[T]) cloneItem(item any) T {
 	if cloneableItem, ok := item.(cdata.ICloneable[T]); ok {
 		return cloneableItem.Clone()
 	}
 
+	// Maps are copied directly to keep value types lost in JSON
+	if mapItem, ok := item.(map[string]any); ok {
+		if newItem, ok := CloneMapValue(mapItem).(T); ok {
+			return newItem
+		}
+	}
+
 	strObject, _ := c.JsonConvertor.ToJson(item.(T))
 	newItem, _ := c.JsonConvertor.FromJson(strObject)
 	return newItem
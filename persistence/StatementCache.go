@@ -0,0 +1,142 @@
+package persistence
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// StatementCache keeps prepared statements for reuse.
+// The number of cached statements is bounded: when the cap is reached
+// the least recently used statement is evicted and closed
+// to stay below max_prepared_stmt_count of the server.
+// Statements are leased to callers: an evicted statement is closed
+// only after all its leases are released.
+type StatementCache struct {
+	lock       sync.Mutex
+	db         *sql.DB
+	capacity   int
+	order      *list.List
+	statements map[string]*list.Element
+}
+
+type cachedStatement struct {
+	query   string
+	stmt    *sql.Stmt
+	leases  int
+	evicted bool
+}
+
+// NewStatementCache creates a new instance of the statement cache.
+//	Parameters:
+//		- db a database to prepare statements in
+//		- capacity a maximum number of cached statements
+//	Returns: created statement cache
+func NewStatementCache(db *sql.DB, capacity int) *StatementCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &StatementCache{
+		db:         db,
+		capacity:   capacity,
+		order:      list.New(),
+		statements: make(map[string]*list.Element),
+	}
+}
+
+// Prepare gets a cached statement for the query or prepares a new one.
+// The statement is leased to the caller and stays open until the returned release function is called,
+// even if it is evicted from the cache meanwhile. Rows returned by the statement keep it open until they are closed.
+//	Parameters:
+//		- ctx context.Context
+//		- query a query to prepare
+//	Returns: prepared statement, a function to release it or error.
+func (c *StatementCache) Prepare(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	c.lock.Lock()
+	if element, ok := c.statements[query]; ok {
+		c.order.MoveToFront(element)
+		cached := element.Value.(*cachedStatement)
+		cached.leases++
+		c.lock.Unlock()
+		return cached.stmt, c.releaser(cached), nil
+	}
+	c.lock.Unlock()
+
+	// Statements are prepared without holding the lock, so other queries aren't blocked by the server round trip
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Another caller could prepare the same query meanwhile
+	if element, ok := c.statements[query]; ok {
+		c.order.MoveToFront(element)
+		cached := element.Value.(*cachedStatement)
+		cached.leases++
+		stmt.Close()
+		return cached.stmt, c.releaser(cached), nil
+	}
+
+	cached := &cachedStatement{query: query, stmt: stmt, leases: 1}
+	c.statements[query] = c.order.PushFront(cached)
+
+	for c.order.Len() > c.capacity {
+		c.evict(c.order.Back())
+	}
+	return stmt, c.releaser(cached), nil
+}
+
+// Len gets a number of cached statements.
+func (c *StatementCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.order.Len()
+}
+
+// Close evicts all cached statements and clears the cache.
+// Leased statements are closed when they are released.
+//	Returns: the first error occurred while closing statements or nil.
+func (c *StatementCache) Close() (err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for c.order.Len() > 0 {
+		if closeErr := c.evict(c.order.Back()); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// evict removes the statement from the cache and closes it when it isn't leased.
+func (c *StatementCache) evict(element *list.Element) error {
+	cached := c.order.Remove(element).(*cachedStatement)
+	delete(c.statements, cached.query)
+	cached.evicted = true
+	if cached.leases > 0 {
+		return nil
+	}
+	return cached.stmt.Close()
+}
+
+// releaser creates a function that releases a lease of the statement once
+// and closes the statement if it was evicted while leased.
+func (c *StatementCache) releaser(cached *cachedStatement) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+
+			cached.leases--
+			if cached.evicted && cached.leases == 0 {
+				cached.stmt.Close()
+			}
+		})
+	}
+}
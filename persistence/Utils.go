@@ -2,8 +2,10 @@ package persistence
 
 import (
 	"reflect"
+	"strings"
 
 	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	cpersist "github.com/pip-services3-gox/pip-services3-data-gox/persistence"
 )
 
@@ -45,3 +47,257 @@ func GetObjectId[K any](obj any) (id K) {
 	}
 	return
 }
+
+// CloneMapValue makes a deep copy of maps and slices in the value,
+// other values are returned as is.
+func CloneMapValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, val := range v {
+			result[key] = CloneMapValue(val)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, val := range v {
+			result[i] = CloneMapValue(val)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// getJsonFieldPositions gets positions of the struct fields in declaration order
+// keyed by their lowercase JSON names, fields of embedded structs are placed inline.
+// It returns nil for types other than structs since they accept any field.
+func getJsonFieldPositions(typ reflect.Type) map[string]int {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	positions := make(map[string]int)
+	for _, name := range getJsonFieldNames(typ) {
+		if _, ok := positions[name]; !ok {
+			positions[name] = len(positions)
+		}
+	}
+	return positions
+}
+
+// getJsonFieldKinds gets kinds of the struct fields keyed by their lowercase JSON names,
+// pointers are dereferenced. It returns nil for types other than structs.
+func getJsonFieldKinds(typ reflect.Type) map[string]reflect.Kind {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	kinds := make(map[string]reflect.Kind)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			for embedded, kind := range getJsonFieldKinds(field.Type) {
+				if _, ok := kinds[embedded]; !ok {
+					kinds[embedded] = kind
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		kinds[strings.ToLower(name)] = fieldType.Kind()
+	}
+	return kinds
+}
+
+// getJsonFieldNames gets lowercase JSON names of the struct fields including fields of embedded structs.
+func getJsonFieldNames(typ reflect.Type) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			names = append(names, getJsonFieldNames(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		// JSON unmarshalling matches field names case-insensitively
+		names = append(names, strings.ToLower(name))
+	}
+	return names
+}
+
+// ReplaceNamedParameters rewrites @name parameters in the query to positional ? placeholders
+// and returns their values in the order of the placeholders, so a parameter can be used several times.
+// Quoted strings and identifiers, comments and @@ system variables are kept as is,
+// MySQL user variables can't be used in queries with named parameters.
+//	Parameters:
+//		- correlationId (optional) transaction id to trace execution through call chain.
+//		- query a query with named parameters
+//		- params values of the parameters keyed by their names without @
+//	Returns: the query with positional placeholders, values of the placeholders
+//	or error when a value of a parameter is not set.
+func ReplaceNamedParameters(correlationId string, query string, params map[string]any) (string, []any, error) {
+	var builder strings.Builder
+	builder.Grow(len(query))
+	args := make([]any, 0, len(params))
+
+	var quote byte
+	// '\n' inside of line comments and '*' inside of block comments
+	var comment byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case comment == '\n':
+			if ch == '\n' {
+				comment = 0
+			}
+		case comment == '*':
+			if ch == '*' && i+1 < len(query) && query[i+1] == '/' {
+				builder.WriteString("*/")
+				i++
+				comment = 0
+				continue
+			}
+		case quote != 0:
+			if ch == '\\' && quote != '`' && i+1 < len(query) {
+				// Escaped characters don't close strings
+				builder.WriteByte(ch)
+				i++
+				ch = query[i]
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '#' || isDashComment(query, i):
+			comment = '\n'
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			builder.WriteString("/*")
+			i++
+			comment = '*'
+			continue
+		case ch == '@' && i+1 < len(query) && query[i+1] == '@':
+			builder.WriteString("@@")
+			i++
+			continue
+		case ch == '@' && i+1 < len(query) && isParameterNameChar(query[i+1], true):
+			end := i + 1
+			for end < len(query) && isParameterNameChar(query[end], false) {
+				end++
+			}
+			name := query[i+1 : end]
+			value, ok := params[name]
+			if !ok {
+				return "", nil, cerr.NewBadRequestError(correlationId, "MISSING_PARAMETER",
+					"Value of query parameter @"+name+" is not set").
+					WithDetails("parameter", name)
+			}
+			builder.WriteByte('?')
+			args = append(args, value)
+			i = end - 1
+			continue
+		}
+		builder.WriteByte(ch)
+	}
+	return builder.String(), args, nil
+}
+
+// isDashComment checks if a -- comment starts at the position.
+// MySQL requires the second dash to be followed by a whitespace or a control character.
+func isDashComment(query string, i int) bool {
+	if query[i] != '-' || i+1 >= len(query) || query[i+1] != '-' {
+		return false
+	}
+	return i+2 == len(query) || query[i+2] <= ' '
+}
+
+func isParameterNameChar(ch byte, first bool) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (!first && ch >= '0' && ch <= '9')
+}
+
+// SplitStatements splits a script into statements separated by semicolons,
+// e.g. to run DDL scripts without enabling multiStatements in the driver.
+// Semicolons in quoted strings, identifiers and comments don't separate statements.
+// DELIMITER commands of the mysql client are not supported.
+//	Parameters:
+//		- script statements separated by semicolons
+//	Returns: trimmed statements without empty ones.
+func SplitStatements(script string) []string {
+	statements := make([]string, 0, 1)
+	start := 0
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && quote != '`' {
+				// Escaped characters don't close strings
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '#' || isDashComment(script, i):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case ch == ';':
+			if statement := strings.TrimSpace(script[start:i]); statement != "" {
+				statements = append(statements, statement)
+			}
+			start = i + 1
+		}
+	}
+	if start < len(script) {
+		if statement := strings.TrimSpace(script[start:]); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
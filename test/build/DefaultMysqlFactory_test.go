@@ -0,0 +1,36 @@
+package test_build
+
+import (
+	"testing"
+
+	cref "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/build"
+	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
+	tpersist "github.com/pip-services3-gox/pip-services3-mysql-gox/test/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultMySqlFactory(t *testing.T) {
+	factory := build.NewDefaultMySqlFactory()
+
+	t.Run("CreateConnection", func(t *testing.T) {
+		descriptor := cref.NewDescriptor("pip-services", "connection", "mysql", "default", "1.0")
+
+		component, err := factory.Create(descriptor)
+		assert.Nil(t, err)
+
+		_, ok := component.(*conn.MySqlConnection)
+		assert.True(t, ok)
+	})
+
+	t.Run("CreatePersistence", func(t *testing.T) {
+		descriptor := cref.NewDescriptor("pip-services", "persistence", "mysql", "dummy", "1.0")
+		factory.RegisterPersistence(descriptor, tpersist.NewDummyMySqlPersistence)
+
+		component, err := factory.Create(descriptor)
+		assert.Nil(t, err)
+
+		_, ok := component.(*tpersist.DummyMySqlPersistence)
+		assert.True(t, ok)
+	})
+}
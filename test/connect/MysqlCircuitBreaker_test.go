@@ -0,0 +1,45 @@
+package test_connect
+
+import (
+	"testing"
+	"time"
+
+	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySqlCircuitBreaker(t *testing.T) {
+	breaker := conn.NewMySqlCircuitBreaker(3, 100*time.Millisecond)
+
+	// Failures below the threshold keep the breaker closed
+	breaker.Failure()
+	breaker.Failure()
+	assert.False(t, breaker.IsOpen())
+	assert.True(t, breaker.Allow())
+
+	// Success resets the number of consecutive failures
+	breaker.Success()
+	breaker.Failure()
+	breaker.Failure()
+	assert.False(t, breaker.IsOpen())
+
+	breaker.Failure()
+	assert.True(t, breaker.IsOpen())
+	assert.False(t, breaker.Allow())
+
+	// A single probe is allowed after the cooldown
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	// Failed probe opens the breaker for another cooldown
+	breaker.Failure()
+	assert.True(t, breaker.IsOpen())
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, breaker.Allow())
+	breaker.Success()
+	assert.False(t, breaker.IsOpen())
+	assert.True(t, breaker.Allow())
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +31,339 @@ func TestMySqlConnectionResolver(t *testing.T) {
 	assert.NotEmpty(t, uri)
 	assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?ssl=false", uri)
 }
+
+func TestMySqlConnectionResolverInterpolateParams(t *testing.T) {
+
+	t.Run("Connection", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "mysql",
+			"options.interpolate_params", true,
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?interpolateParams=true", uri)
+	})
+
+	t.Run("Uri", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.uri", "mysql:mysql@tcp(localhost:3306)/test?parseTime=true",
+			"options.interpolate_params", true,
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?parseTime=true&interpolateParams=true", uri)
+	})
+}
+
+func TestMySqlConnectionResolverCredentials(t *testing.T) {
+
+	t.Run("EmptyPassword", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql@tcp(localhost:3306)/test", uri)
+	})
+
+	t.Run("MissingUsername", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.password", "mysql",
+		))
+
+		_, err := resolver.Resolve(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.Equal(t, "NO_USERNAME", err.(*cerr.ApplicationError).Code)
+	})
+
+	t.Run("UnresolvedPlaceholder", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "${MYSQL_PASSWORD}",
+		))
+
+		_, err := resolver.Resolve(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.Equal(t, "UNRESOLVED_CREDENTIAL", err.(*cerr.ApplicationError).Code)
+		assert.Contains(t, err.Error(), "password")
+	})
+
+	t.Run("SpecialChars", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "app@host/db",
+			"credential.password", "a:b@c/d(e)?f#",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+
+		config, err := mysql.ParseDSN(uri)
+		assert.Nil(t, err)
+		if err == nil {
+			assert.Equal(t, "app@host/db", config.User)
+			assert.Equal(t, "a:b@c/d(e)?f#", config.Passwd)
+			assert.Equal(t, "localhost:3306", config.Addr)
+			assert.Equal(t, "test", config.DBName)
+		}
+	})
+
+	t.Run("ColonInUsername", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "app:user",
+			"credential.password", "mysql",
+		))
+
+		_, err := resolver.Resolve(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.Equal(t, "INVALID_CREDENTIAL", err.(*cerr.ApplicationError).Code)
+	})
+}
+
+func TestMySqlConnectionResolverConflictingParams(t *testing.T) {
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connections.0.host", "host1",
+		"connections.0.port", 3306,
+		"connections.0.database", "test",
+		"connections.0.charset", "utf8",
+		"connections.1.host", "host2",
+		"connections.1.port", 3306,
+		"connections.1.database", "test",
+		"connections.1.charset", "latin1",
+		"connections.10.host", "host10",
+		"connections.10.port", 3306,
+		"connections.10.database", "test",
+		"connections.10.charset", "cp1251",
+		"connections.2.host", "host3",
+		"connections.2.port", 3306,
+		"connections.2.database", "test",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+	)
+
+	// Sections are read from a map, so the order is checked several times
+	for i := 0; i < 20; i++ {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), dbConfig)
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		// Connections are ordered by their indexes and the value from the first connection takes precedence
+		assert.Equal(t, "mysql:mysql@tcp(host1:3306,host2:3306,host3:3306,host10:3306)/test?charset=utf8", uri)
+	}
+}
+
+func TestMySqlConnectionResolverDiscoveryKey(t *testing.T) {
+	resolver := conn.NewMySqlConnectionResolver()
+	resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"connection.discovery_key", "mysql_main",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+	))
+
+	_, err := resolver.Resolve(context.Background(), "123")
+	assert.NotNil(t, err)
+
+	appErr := err.(*cerr.ApplicationError)
+	assert.Equal(t, "NO_DISCOVERY", appErr.Code)
+	assert.Equal(t, "123", appErr.CorrelationId)
+	assert.Contains(t, appErr.Message, "mysql_main")
+	assert.Equal(t, "mysql_main", appErr.Details["discovery_key"])
+
+	// The connection fails to open with the same cause
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"connection.discovery_key", "mysql_main",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+	))
+
+	err = connection.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.False(t, connection.IsOpen())
+
+	appErr = err.(*cerr.ApplicationError)
+	assert.Equal(t, "CONNECT_FAILED", appErr.Code)
+	assert.Contains(t, appErr.Cause, "mysql_main")
+}
+
+func TestMySqlConnectionResolverSqlMode(t *testing.T) {
+
+	t.Run("Connection", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "mysql",
+			"options.sql_mode", "STRICT_ALL_TABLES,NO_ZERO_DATE",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?sql_mode=%27STRICT_ALL_TABLES%2CNO_ZERO_DATE%27", uri)
+	})
+
+	t.Run("Uri", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.uri", "mysql:mysql@tcp(localhost:3306)/test?parseTime=true",
+			"options.sql_mode", "STRICT_ALL_TABLES",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?parseTime=true&sql_mode=%27STRICT_ALL_TABLES%27", uri)
+	})
+}
+
+func TestMySqlConnectionResolverSpecialChars(t *testing.T) {
+	resolver := conn.NewMySqlConnectionResolver()
+	resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"connection.host", "localhost",
+		"connection.port", 3306,
+		"connection.database", "test",
+		"connection.loc", "America/New_York",
+		"connection.time_zone", "'+03:00'",
+		"connection.collation", "utf8mb4_unicode_ci",
+		"credential.username", "mysql",
+		"credential.password", "p@ss:w/rd?&=%",
+		"options.sql_mode", "STRICT_ALL_TABLES,NO_ZERO_DATE",
+	))
+
+	uri, err := resolver.Resolve(context.Background(), "")
+	assert.Nil(t, err)
+
+	// The driver must get back every component as it was configured
+	config, err := mysql.ParseDSN(uri)
+	assert.Nil(t, err)
+	if err == nil {
+		assert.Equal(t, "mysql", config.User)
+		assert.Equal(t, "p@ss:w/rd?&=%", config.Passwd)
+		assert.Equal(t, "localhost:3306", config.Addr)
+		assert.Equal(t, "test", config.DBName)
+		assert.Equal(t, "America/New_York", config.Loc.String())
+		assert.Equal(t, "utf8mb4_unicode_ci", config.Collation)
+		assert.Equal(t, "'+03:00'", config.Params["time_zone"])
+		assert.Equal(t, "'STRICT_ALL_TABLES,NO_ZERO_DATE'", config.Params["sql_mode"])
+	}
+}
+
+func TestMySqlConnectionResolverTimezone(t *testing.T) {
+
+	t.Run("Connection", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "mysql",
+			"options.timezone", "Asia/Tokyo",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+
+		config, err := mysql.ParseDSN(uri)
+		assert.Nil(t, err)
+		if err == nil {
+			assert.Equal(t, "Asia/Tokyo", config.Loc.String())
+			assert.True(t, config.ParseTime)
+			assert.Equal(t, "'Asia/Tokyo'", config.Params["time_zone"])
+		}
+	})
+
+	t.Run("Uri", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.uri", "mysql:mysql@tcp(localhost:3306)/test?parseTime=false",
+			"options.timezone", "Asia/Tokyo",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?parseTime=false&loc=Asia%2FTokyo&time_zone=%27Asia%2FTokyo%27", uri)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"options.timezone", "Mars/Olympus",
+		))
+
+		_, err := resolver.Resolve(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.Equal(t, "INVALID_TIMEZONE", err.(*cerr.ApplicationError).Code)
+	})
+}
+
+func TestMySqlConnectionResolverApplicationName(t *testing.T) {
+
+	t.Run("Connection", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "localhost",
+			"connection.port", 3306,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "mysql",
+			"options.application_name", "billing's service, eu",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+
+		// Commas would split the attribute, so they are replaced
+		config, err := mysql.ParseDSN(uri)
+		assert.Nil(t, err)
+		if err == nil {
+			assert.Equal(t, "program_name:billing's service  eu", config.ConnectionAttributes)
+		}
+	})
+
+	t.Run("Uri", func(t *testing.T) {
+		resolver := conn.NewMySqlConnectionResolver()
+		resolver.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.uri", "mysql:mysql@tcp(localhost:3306)/test?parseTime=true",
+			"options.application_name", "billing",
+		))
+
+		uri, err := resolver.Resolve(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql:mysql@tcp(localhost:3306)/test?parseTime=true&connectionAttributes=program_name%3Abilling", uri)
+	})
+}
@@ -2,10 +2,14 @@ package test_connect
 
 import (
 	"context"
+	"database/sql"
+	"net"
 	"os"
 	"testing"
+	"time"
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,3 +67,290 @@ func TestMySqlConnection(t *testing.T) {
 	err = connection.Close(context.Background(), "")
 	assert.Nil(t, err)
 }
+
+func TestMySqlConnectionRetries(t *testing.T) {
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connection.host", "127.0.0.1",
+		"connection.port", 1,
+		"connection.database", "test",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+		"options.max_retries", 1,
+		"options.retry_backoff_ms", 10000,
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+
+	start := time.Now()
+	err := connection.Open(context.Background(), "")
+	assert.NotNil(t, err)
+	assert.False(t, connection.IsOpen())
+	// With a single attempt it must fail without waiting for backoff
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestMySqlConnectionOpenCancelled(t *testing.T) {
+	// The server accepts connections but never sends the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			socket, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer socket.Close()
+		}
+	}()
+
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connection.host", "127.0.0.1",
+		"connection.port", listener.Addr().(*net.TCPAddr).Port,
+		"connection.database", "test",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+		"options.max_retries", 3,
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = connection.Open(ctx, "123")
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.NotNil(t, err)
+	assert.False(t, connection.IsOpen())
+
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, "CONTEXT_CANCELLED", appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+		assert.Contains(t, appErr.Cause, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestMySqlConnectionOnError(t *testing.T) {
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connection.host", "127.0.0.1",
+		"connection.port", 1,
+		"connection.database", "test",
+		"credential.username", "mysql",
+		"credential.password", "mysql",
+		"options.max_retries", 1,
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+
+	var errs []error
+	connection.OnError = func(ctx context.Context, correlationId string, err error) {
+		errs = append(errs, err)
+	}
+
+	err := connection.Open(context.Background(), "")
+	assert.NotNil(t, err)
+	assert.Equal(t, []error{err}, errs)
+}
+
+func TestMySqlConnectionInvalidConfig(t *testing.T) {
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connection.host", "localhost",
+		"connection.port", 3306,
+		"connection.database", "test",
+		"credential.username", "mysql",
+		"credential.password", "${MYSQL_PASSWORD}",
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+
+	var errs []error
+	connection.OnError = func(ctx context.Context, correlationId string, err error) {
+		errs = append(errs, err)
+	}
+
+	err := connection.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.False(t, connection.IsOpen())
+	assert.Equal(t, []error{err}, errs)
+
+	appErr := err.(*cerr.ApplicationError)
+	assert.Equal(t, "CONNECT_FAILED", appErr.Code)
+	assert.Equal(t, "123", appErr.CorrelationId)
+	assert.Contains(t, appErr.Cause, "${MYSQL_PASSWORD}")
+}
+
+func TestMySqlConnectionWarmup(t *testing.T) {
+	dbConfig := newTestDbConfig(t,
+		"options.max_pool_size", 10,
+		"options.connect_timeout", 60000,
+		"options.idle_timeout", 60000,
+		"options.warmup_connections", 5,
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	if err != nil {
+		t.Error("Error opened connection", err)
+		return
+	}
+	defer connection.Close(context.Background(), "")
+
+	stats := connection.GetConnection().Stats()
+	assert.Equal(t, 5, stats.OpenConnections)
+	assert.Equal(t, 5, stats.Idle)
+}
+
+func TestMySqlConnectionCallbacks(t *testing.T) {
+	dbConfig := newTestDbConfig(t)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+
+	opened, closed := 0, 0
+	connection.OnOpen = func(ctx context.Context, correlationId string, pool *sql.DB) error {
+		opened++
+		assert.Nil(t, pool.PingContext(ctx))
+		return nil
+	}
+	connection.OnClose = func(ctx context.Context, correlationId string) {
+		closed++
+	}
+	connection.OnError = func(ctx context.Context, correlationId string, err error) {
+		t.Error("Unexpected connection error", err)
+	}
+
+	err := connection.Open(context.Background(), "")
+	assert.Nil(t, err)
+	assert.True(t, connection.IsOpen())
+	assert.Equal(t, 1, opened)
+	assert.Equal(t, 0, closed)
+
+	err = connection.Close(context.Background(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened)
+	assert.Equal(t, 1, closed)
+}
+
+func TestMySqlConnectionSqlMode(t *testing.T) {
+	dbConfig := newTestDbConfig(t,
+		"options.sql_mode", "STRICT_ALL_TABLES,NO_ZERO_DATE",
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer connection.Close(context.Background(), "")
+
+	// Each pooled connection gets the mode, so check several of them at once
+	pool := connection.GetConnection()
+	conns := make([]*sql.Conn, 0)
+	for i := 0; i < 3; i++ {
+		dbConn, err := pool.Conn(context.Background())
+		assert.Nil(t, err)
+		conns = append(conns, dbConn)
+
+		var sqlMode string
+		err = dbConn.QueryRowContext(context.Background(), "SELECT @@SESSION.sql_mode").Scan(&sqlMode)
+		assert.Nil(t, err)
+		assert.Equal(t, "STRICT_ALL_TABLES,NO_ZERO_DATE", sqlMode)
+	}
+	for _, dbConn := range conns {
+		dbConn.Close()
+	}
+}
+
+func TestMySqlConnectionTimezone(t *testing.T) {
+	dbConfig := newTestDbConfig(t,
+		"options.timezone", "Asia/Tokyo",
+	)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer connection.Close(context.Background(), "")
+
+	pool := connection.GetConnection()
+	_, err = pool.Exec("DROP TABLE IF EXISTS `timestamps`")
+	assert.Nil(t, err)
+	_, err = pool.Exec("CREATE TABLE `timestamps` (`id` INT PRIMARY KEY, `time` DATETIME)")
+	assert.Nil(t, err)
+	defer pool.Exec("DROP TABLE IF EXISTS `timestamps`")
+
+	// The time is written as the wall clock time in the configured zone
+	value := time.Date(2022, time.March, 1, 12, 30, 0, 0, time.UTC)
+	_, err = pool.Exec("INSERT INTO `timestamps` (`id`, `time`) VALUES (?, ?)", 1, value)
+	assert.Nil(t, err)
+
+	var stored string
+	var result time.Time
+	err = pool.QueryRow("SELECT CAST(`time` AS CHAR), `time` FROM `timestamps` WHERE `id`=?", 1).Scan(&stored, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "2022-03-01 21:30:00", stored)
+	assert.True(t, value.Equal(result))
+	assert.Equal(t, "Asia/Tokyo", result.Location().String())
+
+	// Server functions use the same zone
+	var timeZone string
+	err = pool.QueryRow("SELECT @@session.time_zone").Scan(&timeZone)
+	assert.Nil(t, err)
+	assert.Equal(t, "Asia/Tokyo", timeZone)
+}
+
+func TestMySqlConnectionTestConnection(t *testing.T) {
+	dbConfig := newTestDbConfig(t)
+
+	t.Run("Valid", func(t *testing.T) {
+		err := conn.TestConnection(context.Background(), dbConfig)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		err := conn.TestConnection(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "127.0.0.1",
+			"connection.port", 1,
+			"connection.database", "test",
+			"credential.username", "mysql",
+			"credential.password", "mysql",
+		))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := conn.TestConnection(context.Background(), cconf.NewConfigParamsFromTuples(
+			"connection.host", "127.0.0.1",
+			"connection.port", 3306,
+		))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMySqlConnectionReconnect(t *testing.T) {
+	dbConfig := newTestDbConfig(t)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer connection.Close(context.Background(), "")
+
+	oldPool := connection.GetConnection()
+	err = connection.Reconnect(context.Background(), "")
+	assert.Nil(t, err)
+	assert.True(t, connection.IsOpen())
+
+	// The new pool replaces the closed one
+	pool := connection.GetConnection()
+	assert.NotSame(t, oldPool, pool)
+	assert.Nil(t, pool.PingContext(context.Background()))
+	assert.NotNil(t, oldPool.PingContext(context.Background()))
+}
@@ -0,0 +1,47 @@
+package test_connect
+
+import (
+	"os"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+)
+
+// newTestDbConfig creates connection parameters of the test database set by environment variables
+// followed by the additional tuples. The test is skipped when the connection is not set.
+func newTestDbConfig(t testing.TB, tuples ...any) *cconf.ConfigParams {
+	mysqlUri := os.Getenv("MYSQL_URI")
+	mysqlHost := os.Getenv("MYSQL_HOST")
+	if mysqlHost == "" {
+		mysqlHost = "localhost"
+	}
+	mysqlPort := os.Getenv("MYSQL_PORT")
+	if mysqlPort == "" {
+		mysqlPort = "3306"
+	}
+	mysqlDatabase := os.Getenv("MYSQL_DB")
+	if mysqlDatabase == "" {
+		mysqlDatabase = "test"
+	}
+	mysqlUser := os.Getenv("MYSQL_USER")
+	if mysqlUser == "" {
+		mysqlUser = "user"
+	}
+	mysqlPassword := os.Getenv("MYSQL_PASSWORD")
+	if mysqlPassword == "" {
+		mysqlPassword = "password"
+	}
+
+	if mysqlUri == "" && mysqlHost == "" {
+		t.Skip("Connection params not set")
+	}
+
+	return cconf.NewConfigParamsFromTuples(append([]any{
+		"connection.uri", mysqlUri,
+		"connection.host", mysqlHost,
+		"connection.port", mysqlPort,
+		"connection.database", mysqlDatabase,
+		"credential.username", mysqlUser,
+		"credential.password", mysqlPassword,
+	}, tuples...)...)
+}
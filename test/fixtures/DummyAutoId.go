@@ -0,0 +1,15 @@
+package fixtures
+
+type DummyAutoId struct {
+	Id      int64  `json:"id"`
+	Key     string `json:"key"`
+	Content string `json:"content"`
+}
+
+func (d *DummyAutoId) SetId(id int64) {
+	d.Id = id
+}
+
+func (d DummyAutoId) GetId() int64 {
+	return d.Id
+}
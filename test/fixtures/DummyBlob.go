@@ -0,0 +1,15 @@
+package fixtures
+
+type DummyBlob struct {
+	Id      string `json:"id"`
+	Key     string `json:"key"`
+	Content []byte `json:"content"`
+}
+
+func (d *DummyBlob) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummyBlob) GetId() string {
+	return d.Id
+}
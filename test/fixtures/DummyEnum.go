@@ -0,0 +1,23 @@
+package fixtures
+
+type DummyStatus int
+
+const (
+	DummyStatusNew DummyStatus = iota
+	DummyStatusActive
+	DummyStatusArchived
+)
+
+type DummyEnum struct {
+	Id     string      `json:"id"`
+	Key    string      `json:"key"`
+	Status DummyStatus `json:"status"`
+}
+
+func (d *DummyEnum) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummyEnum) GetId() string {
+	return d.Id
+}
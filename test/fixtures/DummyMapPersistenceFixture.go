@@ -28,6 +28,8 @@ func (c *DummyMapPersistenceFixture) TestCrudOperations(t *testing.T) {
 
 	result, err := c.persistence.Create(context.Background(), "", c.dummy1)
 	assert.Nil(t, err)
+	// Caller's item must not be changed
+	assert.Equal(t, "", c.dummy1["id"])
 
 	dummy1 = result
 	assert.NotNil(t, dummy1)
@@ -0,0 +1,20 @@
+package fixtures
+
+type DummyAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type DummyNested struct {
+	Id      string       `json:"id"`
+	Key     string       `json:"key"`
+	Address DummyAddress `json:"address"`
+}
+
+func (d *DummyNested) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummyNested) GetId() string {
+	return d.Id
+}
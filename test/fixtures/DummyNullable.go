@@ -0,0 +1,15 @@
+package fixtures
+
+type DummyNullable struct {
+	Id      string  `json:"id"`
+	Key     string  `json:"key"`
+	Content *string `json:"content"`
+}
+
+func (d *DummyNullable) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummyNullable) GetId() string {
+	return d.Id
+}
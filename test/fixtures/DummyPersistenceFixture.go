@@ -28,6 +28,8 @@ func (c *DummyPersistenceFixture) TestCrudOperations(t *testing.T) {
 
 	result, err := c.persistence.Create(context.Background(), "", c.dummy1)
 	assert.Nil(t, err)
+	// Caller's item must not be changed
+	assert.Equal(t, "", c.dummy1.Id)
 
 	dummy1 = result
 	assert.NotEqual(t, Dummy{}, dummy1)
@@ -209,3 +211,26 @@ func (c *DummyPersistenceFixture) TestRandomOperation(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEqual(t, Dummy{}, result)
 }
+
+func (c *DummyPersistenceFixture) TestCountOperation(t *testing.T) {
+	// Count in empty table
+	count, err := c.persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// Create dummies
+	_, err = c.persistence.Create(context.Background(), "", c.dummy1)
+	assert.Nil(t, err)
+
+	_, err = c.persistence.Create(context.Background(), "", c.dummy2)
+	assert.Nil(t, err)
+
+	count, err = c.persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Count by filter
+	count, err = c.persistence.GetCountByFilter(context.Background(), "", *cdata.NewFilterParamsFromTuples("Key", c.dummy1.Key))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
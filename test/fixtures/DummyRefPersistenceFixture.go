@@ -29,6 +29,9 @@ func (c *DummyRefPersistenceFixture) TestCrudOperations(t *testing.T) {
 	result, err := c.persistence.Create(context.Background(), "", c.dummy1)
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
+	// Caller's item must not be changed
+	assert.Equal(t, "", c.dummy1.Id)
+	assert.NotSame(t, c.dummy1, result)
 
 	dummy1 = *result
 	assert.NotEqual(t, Dummy{}, dummy1)
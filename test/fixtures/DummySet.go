@@ -0,0 +1,15 @@
+package fixtures
+
+type DummySet struct {
+	Id   string   `json:"id"`
+	Key  string   `json:"key"`
+	Tags []string `json:"tags"`
+}
+
+func (d *DummySet) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummySet) GetId() string {
+	return d.Id
+}
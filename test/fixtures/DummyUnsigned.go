@@ -0,0 +1,14 @@
+package fixtures
+
+type DummyUnsigned struct {
+	Id  uint64 `json:"id"`
+	Key string `json:"key"`
+}
+
+func (d *DummyUnsigned) SetId(id uint64) {
+	d.Id = id
+}
+
+func (d DummyUnsigned) GetId() uint64 {
+	return d.Id
+}
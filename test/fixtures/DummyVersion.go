@@ -0,0 +1,16 @@
+package fixtures
+
+type DummyVersion struct {
+	Id      string `json:"id"`
+	Key     string `json:"key"`
+	Content string `json:"content"`
+	Version int64  `json:"version"`
+}
+
+func (d *DummyVersion) SetId(id string) {
+	d.Id = id
+}
+
+func (d DummyVersion) GetId() string {
+	return d.Id
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"context"
+	"sync"
+
+	ccache "github.com/pip-services3-gox/pip-services3-components-gox/cache"
+)
+
+// captureCache keeps stored values in memory and counts hits and removals to be checked in tests
+type captureCache struct {
+	*ccache.NullCache
+	lock     sync.Mutex
+	values   map[string]any
+	hits     int
+	removals []string
+}
+
+func newCaptureCache() *captureCache {
+	return &captureCache{NullCache: ccache.NewNullCache(), values: make(map[string]any)}
+}
+
+func (c *captureCache) Retrieve(ctx context.Context, correlationId string, key string) (any, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	value, ok := c.values[key]
+	if ok {
+		c.hits++
+	}
+	return value, nil
+}
+
+func (c *captureCache) Store(ctx context.Context, correlationId string, key string, value any, timeout int64) (any, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values[key] = value
+	return value, nil
+}
+
+func (c *captureCache) Remove(ctx context.Context, correlationId string, key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.values, key)
+	c.removals = append(c.removals, key)
+	return nil
+}
+
+// Hits returns the number of values found in the cache
+func (c *captureCache) Hits() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"context"
+	"sync"
+
+	ccount "github.com/pip-services3-gox/pip-services3-components-gox/count"
+)
+
+// captureCounters keeps names of recorded timings and incremented counters to be checked in tests
+type captureCounters struct {
+	*ccount.NullCounters
+	lock       sync.Mutex
+	timings    []string
+	increments []string
+}
+
+func newCaptureCounters() *captureCounters {
+	return &captureCounters{NullCounters: ccount.NewNullCounters()}
+}
+
+func (c *captureCounters) EndTiming(ctx context.Context, name string, elapsed float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.timings = append(c.timings, name)
+}
+
+func (c *captureCounters) IncrementOne(ctx context.Context, name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.increments = append(c.increments, name)
+}
+
+func (c *captureCounters) Timings() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.timings...)
+}
+
+func (c *captureCounters) Increments() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.increments...)
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	clog "github.com/pip-services3-gox/pip-services3-components-gox/log"
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+)
+
+// captureLogger keeps written log messages to be checked in tests
+type captureLogger struct {
+	lock           sync.Mutex
+	level          clog.LevelType
+	correlationIds []string
+	// CorrelationIds extracted from contexts passed with messages
+	contextIds []string
+	messages   []string
+}
+
+func (c *captureLogger) capture(ctx context.Context, correlationId string, message string, args []any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.correlationIds = append(c.correlationIds, correlationId)
+	if contextId, ok := persist.GetCorrelationIdFromContext(ctx); ok {
+		c.contextIds = append(c.contextIds, contextId)
+	}
+	c.messages = append(c.messages, fmt.Sprintf(message, args...))
+}
+
+func (c *captureLogger) Level() clog.LevelType         { return c.level }
+func (c *captureLogger) SetLevel(value clog.LevelType) { c.level = value }
+func (c *captureLogger) Log(ctx context.Context, level clog.LevelType, correlationId string, err error, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Fatal(ctx context.Context, correlationId string, err error, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Error(ctx context.Context, correlationId string, err error, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Warn(ctx context.Context, correlationId string, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Info(ctx context.Context, correlationId string, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Debug(ctx context.Context, correlationId string, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+func (c *captureLogger) Trace(ctx context.Context, correlationId string, message string, args ...any) {
+	c.capture(ctx, correlationId, message, args)
+}
+
+// Messages returns a copy of captured messages
+func (c *captureLogger) Messages() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.messages...)
+}
+
+// ContextIds returns a copy of correlationIds extracted from contexts
+func (c *captureLogger) ContextIds() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.contextIds...)
+}
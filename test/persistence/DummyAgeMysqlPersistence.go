@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+)
+
+type DummyAgeMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[map[string]any, string]
+}
+
+func NewDummyAgeMySqlPersistence() *DummyAgeMySqlPersistence {
+	c := &DummyAgeMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[map[string]any, string](c, "dummies_age")
+	return c
+}
+
+func (c *DummyAgeMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `age` INT)")
+	c.EnsureIndex(c.TableName+"_age", map[string]string{"age": "1"}, nil)
+}
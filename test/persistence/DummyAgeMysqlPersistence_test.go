@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyAgeMySqlPersistence(t *testing.T) {
+
+	var persistence *DummyAgeMySqlPersistence
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence = NewDummyAgeMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyAgeMySqlPersistence:CastFilterValues", func(t *testing.T) {
+		for i, age := range []int{20, 30, 40} {
+			_, err := persistence.Create(context.Background(), "", map[string]any{
+				"id": "", "key": "Key " + strconv.Itoa(i+1), "age": age,
+			})
+			assert.Nil(t, err)
+		}
+
+		// Filter values from FilterParams come as strings
+		filter, param := persistence.GenerateEqualFilter("age", "30")
+		assert.Equal(t, "`age`=?", filter)
+		assert.Equal(t, int64(30), param)
+
+		items, err := persistence.GetListByFilter(context.Background(), "", filter, "", "", param)
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, "Key 2", items[0]["key"])
+
+		// The comparison can use the index on the column
+		rows, err := persistence.Client.QueryContext(context.Background(),
+			"EXPLAIN SELECT * FROM "+persistence.QuotedTableName()+" WHERE "+filter, param)
+		assert.Nil(t, err)
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		assert.Nil(t, err)
+		assert.True(t, rows.Next())
+
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		assert.Nil(t, rows.Scan(pointers...))
+
+		for i, column := range columns {
+			if column == "possible_keys" {
+				assert.Contains(t, values[i].String, "dummies_age_age")
+			}
+		}
+	})
+
+	t.Run("DummyAgeMySqlPersistence:GenerateFilter", func(t *testing.T) {
+		filter, args := persistence.GenerateFilter(
+			*cdata.NewFilterParamsFromTuples("FromAge", "25", "ToAge", "40"), "key", "age")
+		assert.Equal(t, "`age`>=? AND `age`<=?", filter)
+		assert.Equal(t, []any{int64(25), int64(40)}, args)
+
+		items, err := persistence.GetListByFilter(context.Background(), "", filter, "`age`", "", args...)
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "Key 2", items[0]["key"])
+		assert.Equal(t, "Key 3", items[1]["key"])
+
+		filter, args = persistence.GenerateFilter(
+			*cdata.NewFilterParamsFromTuples("KeyIn", "Key 1,Key 3"), "key", "age")
+		assert.Equal(t, "`key` IN (?,?)", filter)
+
+		items, err = persistence.GetListByFilter(context.Background(), "", filter, "`key`", "", args...)
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "Key 1", items[0]["key"])
+		assert.Equal(t, "Key 3", items[1]["key"])
+	})
+}
+
+func TestDummyAgeMySqlPersistenceGenerateFilter(t *testing.T) {
+	persistence := NewDummyAgeMySqlPersistence()
+
+	filter, args := persistence.GenerateFilter(*cdata.NewFilterParamsFromTuples(
+		"from_age", "20", "TO_AGE", "30", "key_in", "Key 1, Key 2", "content", "ignored",
+	), "key", "age")
+	assert.Equal(t, "`key` IN (?,?) AND `age`>=? AND `age`<=?", filter)
+	assert.Equal(t, []any{"Key 1", "Key 2", "20", "30"}, args)
+
+	filter, args = persistence.GenerateFilter(*cdata.NewFilterParamsFromTuples("Key", "Key 1"), "key", "age")
+	assert.Equal(t, "`key`=?", filter)
+	assert.Equal(t, []any{"Key 1"}, args)
+
+	// Scalar values with commas are matched as is
+	filter, args = persistence.GenerateFilter(*cdata.NewFilterParamsFromTuples("Key", "Key 1, Key 2"), "key", "age")
+	assert.Equal(t, "`key`=?", filter)
+	assert.Equal(t, []any{"Key 1, Key 2"}, args)
+
+	filter, args = persistence.GenerateFilter(*cdata.NewEmptyFilterParams(), "key", "age")
+	assert.Equal(t, "", filter)
+	assert.Empty(t, args)
+}
@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyAutoIdMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyAutoId, int64]
+}
+
+func NewDummyAutoIdMySqlPersistence() *DummyAutoIdMySqlPersistence {
+	c := &DummyAutoIdMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyAutoId, int64](c, "dummies_auto_id")
+	return c
+}
+
+func (c *DummyAutoIdMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id BIGINT AUTO_INCREMENT PRIMARY KEY, `key` VARCHAR(50), `content` TEXT)")
+}
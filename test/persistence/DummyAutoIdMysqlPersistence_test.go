@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyAutoIdMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.auto_increment_id", true,
+	)
+
+	persistence := NewDummyAutoIdMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyAutoIdMySqlPersistence:Create", func(t *testing.T) {
+		dummy1, err := persistence.Create(context.Background(), "", tf.DummyAutoId{Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		assert.NotEqual(t, int64(0), dummy1.Id)
+		assert.Equal(t, "Key 1", dummy1.Key)
+		assert.Equal(t, "Content 1", dummy1.Content)
+
+		dummy2, err := persistence.Create(context.Background(), "", tf.DummyAutoId{Key: "Key 2", Content: "Content 2"})
+		assert.Nil(t, err)
+		assert.Greater(t, dummy2.Id, dummy1.Id)
+
+		// The generated id refers to the stored item
+		result, err := persistence.GetOneById(context.Background(), "", dummy2.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy2, result)
+	})
+
+	t.Run("DummyAutoIdMySqlPersistence:Truncate", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "", tf.DummyAutoId{Key: "Key 3", Content: "Content 3"})
+		assert.Nil(t, err)
+
+		err = persistence.Truncate(context.Background(), "")
+		assert.Nil(t, err)
+
+		count, err := persistence.GetCountByFilter(context.Background(), "", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		// AUTO_INCREMENT starts over
+		dummy, err := persistence.Create(context.Background(), "", tf.DummyAutoId{Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), dummy.Id)
+	})
+}
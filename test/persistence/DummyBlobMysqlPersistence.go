@@ -0,0 +1,22 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyBlobMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyBlob, string]
+}
+
+func NewDummyBlobMySqlPersistence() *DummyBlobMySqlPersistence {
+	c := &DummyBlobMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyBlob, string](c, "dummies_blob")
+	return c
+}
+
+func (c *DummyBlobMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` BLOB, `location` POINT)")
+	c.EnsureIndex(c.TableName+"_key", map[string]string{"key": "1"}, map[string]string{"unique": "true"})
+}
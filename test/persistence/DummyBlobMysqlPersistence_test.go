@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyBlobMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.binary_encoding", "base64",
+	)
+
+	persistence := NewDummyBlobMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyBlobMySqlPersistence:ReadBinary", func(t *testing.T) {
+		// Bytes that are not valid UTF-8
+		content := []byte{0x00, 0xff, 0xfe, 0x80, 'a'}
+		_, err := persistence.Client.ExecContext(context.Background(),
+			"INSERT INTO `dummies_blob` (id, `key`, `content`, `location`) VALUES ('1', 'Key 1', ?, ST_GeomFromText('POINT(1 2)'))",
+			content)
+		assert.Nil(t, err)
+
+		result, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Key 1", result.Key)
+		assert.Equal(t, content, result.Content)
+
+		var location []byte
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `location` FROM `dummies_blob` WHERE id='1'").Scan(&location)
+		assert.Nil(t, err)
+
+		// Binary and spatial values are returned in base64
+		page, err := persistence.GetMapPageByFilter(context.Background(), "",
+			"", *cdata.NewEmptyPagingParams(), "", "`content`, `location`")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(content), page.Data[0]["content"])
+		assert.Equal(t, base64.StdEncoding.EncodeToString(location), page.Data[0]["location"])
+	})
+
+	if err := persistence.Clear(context.Background(), ""); err != nil {
+		t.Error("Error cleaned persistence", err)
+		return
+	}
+
+	t.Run("DummyBlobMySqlPersistence:WriteBinary", func(t *testing.T) {
+		content := []byte{0x00, 0xff, 0xfe, 0x80, 'b'}
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.DummyBlob{Id: "2", Key: "Key 2", Content: content})
+		assert.Nil(t, err)
+		assert.Equal(t, content, dummy.Content)
+
+		// Bytes are stored as is instead of their base64 text
+		var stored []byte
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `content` FROM `dummies_blob` WHERE id='2'").Scan(&stored)
+		assert.Nil(t, err)
+		assert.Equal(t, content, stored)
+
+		result, err := persistence.GetOneById(context.Background(), "", "2")
+		assert.Nil(t, err)
+		assert.Equal(t, content, result.Content)
+	})
+
+	t.Run("DummyBlobMySqlPersistence:HexEncoding", func(t *testing.T) {
+		hexPersistence := NewDummyMapMySqlPersistence()
+		hexPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"table", "dummies_blob",
+			"options.binary_encoding", "hex",
+		)))
+		err := hexPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer hexPersistence.Close(context.Background(), "")
+
+		result, err := hexPersistence.Create(context.Background(), "",
+			map[string]any{"id": "3", "key": "Key 3", "content": "00fffe8063"})
+		assert.Nil(t, err)
+		assert.Equal(t, "00fffe8063", result["content"])
+
+		var stored []byte
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `content` FROM `dummies_blob` WHERE id='3'").Scan(&stored)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{0x00, 0xff, 0xfe, 0x80, 'c'}, stored)
+	})
+
+	t.Run("DummyBlobMySqlPersistence:InvalidValue", func(t *testing.T) {
+		mapPersistence := NewDummyMapMySqlPersistence()
+		mapPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"table", "dummies_blob",
+		)))
+		err := mapPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer mapPersistence.Close(context.Background(), "")
+
+		_, err = mapPersistence.Create(context.Background(), "",
+			map[string]any{"id": "4", "key": "Key 4", "content": "not base64!"})
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "INVALID_BINARY_VALUE", appErr.Code)
+			assert.Equal(t, "content", appErr.Details["column"])
+		}
+	})
+
+	t.Run("DummyBlobMySqlPersistence:NoEncoding", func(t *testing.T) {
+		rawPersistence := NewDummyMapMySqlPersistence()
+		rawPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"table", "dummies_blob",
+			"options.binary_encoding", "none",
+		)))
+		err := rawPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer rawPersistence.Close(context.Background(), "")
+
+		// Strings are written and read as is, even when they look encoded
+		result, err := rawPersistence.Create(context.Background(), "",
+			map[string]any{"id": "5", "key": "Key 5", "content": "test"})
+		assert.Nil(t, err)
+		assert.Equal(t, "test", result["content"])
+
+		var stored []byte
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `content` FROM `dummies_blob` WHERE id='5'").Scan(&stored)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("test"), stored)
+	})
+
+	t.Run("DummyBlobMySqlPersistence:InvalidEncoding", func(t *testing.T) {
+		invalidPersistence := NewDummyBlobMySqlPersistence()
+		invalidPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"options.binary_encoding", "base32",
+		)))
+		err := invalidPersistence.Open(context.Background(), "")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyEnumMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyEnum, string]
+}
+
+func NewDummyEnumMySqlPersistence() *DummyEnumMySqlPersistence {
+	c := &DummyEnumMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyEnum, string](c, "dummies_enum")
+	return c
+}
+
+func (c *DummyEnumMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `status` INT)")
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyEnumMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyEnumMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyEnumMySqlPersistence:IntegerEnum", func(t *testing.T) {
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.DummyEnum{Id: "1", Key: "Key 1", Status: tf.DummyStatusActive})
+		assert.Nil(t, err)
+		assert.Equal(t, tf.DummyStatusActive, dummy.Status)
+
+		result, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy, result)
+		assert.Equal(t, tf.DummyStatusActive, result.Status)
+
+		// Zero value survives the round-trip as well
+		dummy, err = persistence.Set(context.Background(), "",
+			tf.DummyEnum{Id: "1", Key: "Key 1", Status: tf.DummyStatusNew})
+		assert.Nil(t, err)
+		assert.Equal(t, tf.DummyStatusNew, dummy.Status)
+	})
+}
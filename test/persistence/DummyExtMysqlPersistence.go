@@ -0,0 +1,23 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyExtMySqlPersistence struct {
+	*DummyMySqlPersistence
+}
+
+func NewDummyExtMySqlPersistence() *DummyExtMySqlPersistence {
+	c := &DummyExtMySqlPersistence{}
+	c.DummyMySqlPersistence = &DummyMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.Dummy, string](c, "dummies_ext")
+	return c
+}
+
+func (c *DummyExtMySqlPersistence) DefineSchema() {
+	c.DummyMySqlPersistence.DefineSchema()
+	c.EnsureSchema("ALTER TABLE `" + c.TableName + "` ADD `extra` VARCHAR(50)")
+	c.EnsureIndex(c.TableName+"_extra", map[string]string{"extra": "1"}, nil)
+}
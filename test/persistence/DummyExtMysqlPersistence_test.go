@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyExtMySqlPersistence(t *testing.T) {
+
+	var persistence *DummyExtMySqlPersistence
+	var fixture tf.DummyPersistenceFixture
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence = NewDummyExtMySqlPersistence()
+	fixture = *tf.NewDummyPersistenceFixture(persistence)
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyExtMySqlPersistence:Schema", func(t *testing.T) {
+		// Objects from both inheritance levels must be created
+		indexes, err := persistence.GetIndexes(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Contains(t, indexes, persistence.TableName+"_key")
+		assert.Contains(t, indexes, persistence.TableName+"_extra")
+	})
+
+	t.Run("DummyExtMySqlPersistence:CRUD", fixture.TestCrudOperations)
+
+	if err := persistence.Clear(context.Background(), ""); err != nil {
+		t.Error("Error cleaned persistence", err)
+		return
+	}
+
+	t.Run("DummyExtMySqlPersistence:StrictColumns", func(t *testing.T) {
+		strictPersistence := NewDummyExtMySqlPersistence()
+		strictPersistence.Configure(context.Background(), dbConfig.Override(
+			cconf.NewConfigParamsFromTuples("options.strict_columns", true),
+		))
+		err := strictPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer strictPersistence.Close(context.Background(), "")
+
+		dummy, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+
+		// The extra column is silently dropped in lenient mode
+		result, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy.Key, result.Key)
+
+		_, err = strictPersistence.GetOneById(context.Background(), "123", dummy.Id)
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "UNMAPPED_COLUMNS", appErr.Code)
+			assert.Equal(t, "123", appErr.CorrelationId)
+			assert.Equal(t, []string{"extra"}, appErr.Details["columns"])
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+// DummyJsonIndexMySqlPersistence declares an index over a JSON expression,
+// which requires MySQL 8.0.13 or higher.
+type DummyJsonIndexMySqlPersistence struct {
+	*persist.IdentifiableJsonMySqlPersistence[fixtures.Dummy, string]
+}
+
+func NewDummyJsonIndexMySqlPersistence() *DummyJsonIndexMySqlPersistence {
+	c := &DummyJsonIndexMySqlPersistence{}
+	c.IdentifiableJsonMySqlPersistence = persist.InheritIdentifiableJsonMySqlPersistence[fixtures.Dummy, string](c, "dummies_json_index")
+	return c
+}
+
+func (c *DummyJsonIndexMySqlPersistence) DefineSchema() {
+	c.EnsureTable("", "")
+	c.EnsureFunctionalIndex(c.TableName+"_content", []string{"CAST(JSON_UNQUOTE(`data`->\"$.content\") AS CHAR(100))"}, nil)
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyJsonIndexMySqlPersistence(t *testing.T) {
+
+	var persistence *DummyJsonIndexMySqlPersistence
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence = NewDummyJsonIndexMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyJsonIndexMySqlPersistence:FunctionalIndex", func(t *testing.T) {
+		indexes, err := persistence.GetIndexes(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Contains(t, indexes, "dummies_json_index_content")
+
+		var expression sql.NullString
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `EXPRESSION` FROM information_schema.STATISTICS WHERE `TABLE_SCHEMA`=DATABASE() AND `TABLE_NAME`=? AND `INDEX_NAME`=?",
+			"dummies_json_index", "dummies_json_index_content").Scan(&expression)
+		assert.Nil(t, err)
+		assert.True(t, expression.Valid)
+	})
+}
@@ -19,36 +19,34 @@ func NewDummyJsonMySqlPersistence() *DummyJsonMySqlPersistence {
 }
 
 func (c *DummyJsonMySqlPersistence) DefineSchema() {
-	c.ClearSchema()
 	c.EnsureTable("", "")
-	c.EnsureSchema("ALTER TABLE `" + c.TableName + "` ADD `data_key` VARCHAR(50) AS (JSON_UNQUOTE(`data`->\"$.key\"))")
+	c.EnsureGeneratedColumn("data_key", "VARCHAR(50)", "JSON_UNQUOTE(`data`->\"$.key\")", false)
 	c.EnsureIndex(c.TableName+"_json_key", map[string]string{"data_key": "1"}, map[string]string{"unique": "true"})
 }
 
 func (c *DummyJsonMySqlPersistence) GetPageByFilter(ctx context.Context, correlationId string,
 	filter cdata.FilterParams, paging cdata.PagingParams) (page cdata.DataPage[fixtures.Dummy], err error) {
 
-	key, ok := filter.GetAsNullableString("Key")
-	filterObj := ""
-	if ok && key != "" {
-		filterObj += "data->'$.key'='" + key + "'"
-	}
-
+	filterObj, args := c.composeFilter(filter)
 	return c.IdentifiableJsonMySqlPersistence.GetPageByFilter(ctx, correlationId,
 		filterObj, paging,
-		"", "",
+		"", "", args...,
 	)
 }
 
 func (c *DummyJsonMySqlPersistence) GetCountByFilter(ctx context.Context, correlationId string,
 	filter cdata.FilterParams) (count int64, err error) {
 
-	filterObj := ""
+	filterObj, args := c.composeFilter(filter)
+	return c.IdentifiableJsonMySqlPersistence.GetCountByFilter(ctx, correlationId, filterObj, args...)
+}
+
+// composeFilter converts filter params to a condition on fields of JSON documents and its parameters
+func (c *DummyJsonMySqlPersistence) composeFilter(filter cdata.FilterParams) (string, []any) {
 	if key, ok := filter.GetAsNullableString("Key"); ok && key != "" {
-		filterObj += "data->'$.key'='" + key + "'"
+		return "data->>'$.key'=?", []any{key}
 	}
-
-	return c.IdentifiableJsonMySqlPersistence.GetCountByFilter(ctx, correlationId, filterObj)
+	return "", nil
 }
 
 func (c *DummyJsonMySqlPersistence) GetOneRandom(ctx context.Context, correlationId string) (item fixtures.Dummy, err error) {
@@ -2,11 +2,19 @@ package test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	cref "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDummyJsonMySqlPersistence(t *testing.T) {
@@ -85,4 +93,206 @@ func TestDummyJsonMySqlPersistence(t *testing.T) {
 
 	t.Run("DummyMySqlConnection:Batch", fixture.TestBatchOperations)
 
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlConnection:Count", fixture.TestCountOperation)
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlConnection:Cursor", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Id: "id_" + strconv.Itoa(i), Key: "Key " + strconv.Itoa(i), Content: "Content"})
+			assert.Nil(t, err)
+		}
+
+		// The generated column is not a field of data items
+		keys := make([]string, 0)
+		var cursor any
+		for {
+			items, next, err := persistence.GetPageByCursor(context.Background(), "", "", "data_key", cursor, 2)
+			assert.Nil(t, err)
+			for _, item := range items {
+				keys = append(keys, item.Key)
+			}
+			if err != nil || next == nil {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, []string{"Key 0", "Key 1", "Key 2", "Key 3", "Key 4"}, keys)
+
+		_, _, err := persistence.GetPageByCursor(context.Background(), "", "", "missing", nil, 2)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("DummyMySqlConnection:UpdateJsonFields", func(t *testing.T) {
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.Dummy{Id: "", Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+
+		_, err = persistence.UpdateJsonFields(context.Background(), "", dummy.Id,
+			map[string]any{"$.extra": map[string]any{"a": 1, "b": 2}})
+		assert.Nil(t, err)
+
+		// Updating a nested field keeps its siblings
+		result, err := persistence.UpdateJsonFields(context.Background(), "", dummy.Id,
+			map[string]any{"$.extra.a": 3, "content": "Content 2"})
+		assert.Nil(t, err)
+		assert.Equal(t, dummy.Id, result.Id)
+		assert.Equal(t, "Key 1", result.Key)
+		assert.Equal(t, "Content 2", result.Content)
+
+		var a, b int
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT JSON_EXTRACT(`data`, '$.extra.a'), JSON_EXTRACT(`data`, '$.extra.b') FROM `dummies_json` WHERE id=?",
+			dummy.Id).Scan(&a, &b)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, a)
+		assert.Equal(t, 2, b)
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlConnection:JsonValidator", func(t *testing.T) {
+		persistence.SetJsonValidator(func(data []byte) error {
+			var doc map[string]any
+			if err := json.Unmarshal(data, &doc); err != nil {
+				return err
+			}
+			if content, _ := doc["content"].(string); content == "" {
+				return errors.New("content is required")
+			}
+			return nil
+		})
+		defer persistence.SetJsonValidator(nil)
+
+		_, err := persistence.Create(context.Background(), "",
+			tf.Dummy{Id: "", Key: "Key 1", Content: ""})
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "INVALID_JSON", appErr.Code)
+		}
+
+		count, err := persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.Dummy{Id: "", Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 1", dummy.Content)
+	})
+}
+
+func TestDummyJsonMySqlPersistenceReturnOnWrite(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.log_queries", true,
+		"options.return_on_write", false,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyJsonMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	dummy, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+
+	logged := len(logger.Messages())
+
+	result, err := persistence.UpdatePartially(context.Background(), "", dummy.Id,
+		*cdata.NewAnyValueMapFromTuples("content", "Content 2"))
+	assert.Nil(t, err)
+	assert.Equal(t, dummy.Id, result.Id)
+	assert.Equal(t, "Content 2", result.Content)
+
+	// The updated document is not read back
+	queries := strings.Join(logger.Messages()[logged:], "\n")
+	assert.Contains(t, queries, "Executing query UPDATE")
+	assert.NotContains(t, queries, "Executing query SELECT")
+}
+
+func TestDummyJsonMySqlPersistenceJsonValidatorErrors(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyJsonMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	invalidErr := errors.New("content is not allowed")
+	persistence.SetJsonValidator(func(data []byte) error {
+		return invalidErr
+	})
+
+	dummy := tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"}
+
+	// Rejected documents are traced with the correlation id and not written
+	_, err := persistence.Create(context.Background(), "123", dummy)
+	var appErr *cerr.ApplicationError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "INVALID_JSON", appErr.Code)
+	assert.Equal(t, "123", appErr.CorrelationId)
+	assert.Equal(t, invalidErr.Error(), appErr.Cause)
+
+	_, err = persistence.Set(context.Background(), "123", dummy)
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "INVALID_JSON", appErr.Code)
+	assert.Equal(t, "123", appErr.CorrelationId)
+
+	_, err = persistence.Update(context.Background(), "123", dummy)
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "INVALID_JSON", appErr.Code)
+	assert.Equal(t, "123", appErr.CorrelationId)
+
+	count, err := persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestDummyJsonMySqlPersistencePageTotal(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyJsonMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	for i := 0; i < 3; i++ {
+		_, err := persistence.Create(context.Background(), "",
+			tf.Dummy{Id: "id_" + strconv.Itoa(i), Key: "Key " + strconv.Itoa(i%2), Content: "Content"})
+		assert.Nil(t, err)
+	}
+
+	// The total is counted with the same JSON condition as the page
+	page, err := persistence.GetPageByFilter(context.Background(), "",
+		*cdata.NewFilterParamsFromTuples("Key", "Key 0"), *cdata.NewPagingParams(0, 10, true))
+	assert.Nil(t, err)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, len(page.Data), page.Total)
+
+	page, err = persistence.GetPageByFilter(context.Background(), "",
+		*cdata.NewFilterParamsFromTuples("Key", "Key 1"), *cdata.NewPagingParams(0, 10, true))
+	assert.Nil(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, len(page.Data), page.Total)
 }
@@ -0,0 +1,17 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyLockMySqlPersistence struct {
+	*DummyMySqlPersistence
+}
+
+func NewDummyLockMySqlPersistence() *DummyLockMySqlPersistence {
+	c := &DummyLockMySqlPersistence{}
+	c.DummyMySqlPersistence = &DummyMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.Dummy, string](c, "dummies_lock")
+	return c
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyLockMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	if err != nil {
+		t.Error("Error opened connection", err)
+		return
+	}
+	defer connection.Close(context.Background(), "")
+	db := connection.GetConnection()
+
+	// Prepare the table without the index defined in the schema
+	_, err = db.ExecContext(context.Background(), "DROP TABLE IF EXISTS `dummies_lock`")
+	assert.Nil(t, err)
+	_, err = db.ExecContext(context.Background(),
+		"CREATE TABLE `dummies_lock` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT)")
+	assert.Nil(t, err)
+
+	// Hold a metadata lock on the table, so the first attempt to create the index times out
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.Nil(t, err)
+	_, err = tx.ExecContext(context.Background(), "SELECT * FROM `dummies_lock`")
+	assert.Nil(t, err)
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+		tx.Rollback()
+	}()
+
+	persistence := NewDummyLockMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		// Unknown connection parameters are set as session variables by the driver
+		"connection.lock_wait_timeout", 1,
+		"options.schema_max_retries", 3,
+		"options.schema_retry_backoff_ms", 1000,
+	)))
+
+	err = persistence.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer persistence.Close(context.Background(), "")
+
+	indexes, err := persistence.GetIndexes(context.Background(), "")
+	assert.Nil(t, err)
+	assert.Contains(t, indexes, "dummies_lock_key")
+}
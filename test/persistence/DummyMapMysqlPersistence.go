@@ -18,7 +18,6 @@ func NewDummyMapMySqlPersistence() *DummyMapMySqlPersistence {
 }
 
 func (c *DummyMapMySqlPersistence) DefineSchema() {
-	c.ClearSchema()
 	c.IdentifiableMySqlPersistence.DefineSchema()
 	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT)")
 	c.EnsureIndex(c.IdentifiableMySqlPersistence.TableName+"_key", map[string]string{"key": "1"}, map[string]string{"unique": "true"})
@@ -7,6 +7,7 @@ import (
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDummyMapMySqlPersistence(t *testing.T) {
@@ -85,4 +86,35 @@ func TestDummyMapMySqlPersistence(t *testing.T) {
 
 	t.Run("DummyMapMySqlPersistence:Batch", fixture.TestBatchOperations)
 
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMapMySqlPersistence:Replace", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "",
+			map[string]any{"id": "replace_1", "key": "Key 1", "content": "Content 1"})
+		assert.Nil(t, err)
+
+		// Set updates only passed columns
+		result, err := persistence.Set(context.Background(), "",
+			map[string]any{"id": "replace_1", "key": "Key 2"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Key 2", result["key"])
+		assert.Equal(t, "Content 1", result["content"])
+
+		// Replace resets columns missing in the item
+		result, err = persistence.Replace(context.Background(), "",
+			map[string]any{"id": "replace_1", "key": "Key 3"})
+		assert.Nil(t, err)
+		assert.Equal(t, "replace_1", result["id"])
+		assert.Equal(t, "Key 3", result["key"])
+		assert.Equal(t, "", result["content"])
+
+		result, err = persistence.GetOneById(context.Background(), "", "replace_1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Key 3", result["key"])
+		assert.Equal(t, "", result["content"])
+	})
 }
@@ -19,7 +19,6 @@ func NewDummyMySqlPersistence() *DummyMySqlPersistence {
 }
 
 func (c *DummyMySqlPersistence) DefineSchema() {
-	c.ClearSchema()
 	c.IdentifiableMySqlPersistence.DefineSchema()
 	// Row name must be in double quotes for properly case!!!
 	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT)")
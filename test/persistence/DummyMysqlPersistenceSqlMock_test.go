@@ -0,0 +1,872 @@
+package test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	cref "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDummyMySqlPersistenceSqlMock runs CRUD operations against a mocked pool,
+// so it doesn't require a MySQL server.
+func TestDummyMySqlPersistenceSqlMock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetClient(db, "test")
+
+	columns := []string{"id", "key", "content"}
+
+	t.Run("DummyMySqlPersistence:Create", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO `dummies` (")).
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		result, err := persistence.Create(context.Background(), "",
+			tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "1", result.Id)
+		assert.Equal(t, "Key 1", result.Key)
+		assert.Equal(t, "Content 1", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:GetOneById", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 1"))
+
+		result, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "1", result.Id)
+		assert.Equal(t, "Key 1", result.Key)
+		assert.Equal(t, "Content 1", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:Update", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `dummies` SET ") + ".+" + regexp.QuoteMeta(" WHERE id=?")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 2"))
+
+		result, err := persistence.Update(context.Background(), "",
+			tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 2"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 2", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:DeleteById", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 2"))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := persistence.DeleteById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "1", result.Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:NotFound", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("2").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		result, err := persistence.GetOneById(context.Background(), "", "2")
+		assert.Nil(t, err)
+		assert.Equal(t, "", result.Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:GetOneByIdTimeout", func(t *testing.T) {
+		// The driver returns the error of the context when the deadline passes
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnError(context.DeadlineExceeded)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := persistence.GetOneById(ctx, "123", "1")
+		assert.NotNil(t, err)
+		assert.True(t, persist.IsTimeoutError(err))
+		assert.Equal(t, "123", err.(*cerr.ApplicationError).CorrelationId)
+		assert.Nil(t, mock.ExpectationsWereMet())
+
+		// Other errors are not timeouts, even when a deadline is set
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnError(&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"})
+
+		_, err = persistence.GetOneById(ctx, "123", "1")
+		assert.NotNil(t, err)
+		assert.False(t, persist.IsTimeoutError(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:Error", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `dummies`")).
+			WillReturnError(errors.New("server has gone away"))
+
+		err := persistence.Clear(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:RetryDeadlock", func(t *testing.T) {
+		retryPersistence := NewDummyMySqlPersistence()
+		retryPersistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"options.write_max_retries", 2,
+			"options.write_retry_backoff_ms", 1,
+		))
+		retryPersistence.SetClient(db, "test")
+
+		// The server aborts the first attempt to resolve a deadlock
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `dummies` SET ")).
+			WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `dummies` SET ")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 3"))
+
+		result, err := retryPersistence.Update(context.Background(), "",
+			tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 3"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 3", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+
+		// The error is returned when all retries fail
+		for i := 0; i < 3; i++ {
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `dummies` SET ")).
+				WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"})
+		}
+
+		_, err = retryPersistence.Update(context.Background(), "",
+			tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 4"})
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:SchemaScript", func(t *testing.T) {
+		schemaPersistence := NewDummyMySqlPersistence()
+		schemaPersistence.SetClient(db, "test")
+		schemaPersistence.EnsureSchemaScript("CREATE TABLE `dummies` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50));\n" +
+			"CREATE INDEX `dummies_key` ON `dummies` (`key`);")
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_NAME=? AND TABLE_SCHEMA=DATABASE()")).
+			WithArgs("dummies").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta("CREATE TABLE `dummies` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50))")).
+			WillReturnRows(sqlmock.NewRows([]string{}))
+		mock.ExpectQuery(regexp.QuoteMeta("CREATE INDEX `dummies_key` ON `dummies` (`key`)")).
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		err := schemaPersistence.CreateSchema(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:MultiKeyIndex", func(t *testing.T) {
+		indexPersistence := NewDummyMySqlPersistence()
+		indexPersistence.SetClient(db, "test")
+		indexPersistence.EnsureIndex("dummies_key_content", map[string]string{"key": "1", "content": "-1"}, nil)
+
+		// Keys are quoted one by one in a stable order with DESC outside of the quotes
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_NAME=? AND TABLE_SCHEMA=DATABASE()")).
+			WithArgs("dummies").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("^" + regexp.QuoteMeta("CREATE INDEX `dummies_key_content` ON `dummies`(`content` DESC, `key`)") + "$").
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		err := indexPersistence.CreateSchema(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:FunctionalIndexRawTable", func(t *testing.T) {
+		indexPersistence := NewDummyJsonIndexMySqlPersistence()
+		indexPersistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"raw_table", "(SELECT * FROM `dummies_json_index` WHERE `id` > '0') AS `dummies_json_index`",
+		))
+		indexPersistence.SetClient(db, "test")
+		indexPersistence.DefineSchema()
+
+		// DDL statements use the table name instead of the raw SQL
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_NAME=? AND TABLE_SCHEMA=DATABASE()")).
+			WithArgs("dummies_json_index").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `dummies_json_index` (")).
+			WillReturnRows(sqlmock.NewRows([]string{}))
+		mock.ExpectQuery(regexp.QuoteMeta("CREATE INDEX `dummies_json_index_content` ON `dummies_json_index` ((CAST(JSON_UNQUOTE(`data`->\"$.content\") AS CHAR(100))))")).
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		err := indexPersistence.CreateSchema(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:GeneratedColumns", func(t *testing.T) {
+		columnPersistence := NewDummyMySqlPersistence()
+		columnPersistence.SetClient(db, "test")
+		columnPersistence.EnsureGeneratedColumn("key_length", "INT", "CHAR_LENGTH(`key`)", true)
+		columnPersistence.EnsureGeneratedColumn("key_upper", "VARCHAR(50)", "UPPER(`key`)", false)
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_NAME=? AND TABLE_SCHEMA=DATABASE()")).
+			WithArgs("dummies").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("^" + regexp.QuoteMeta("ALTER TABLE `dummies` ADD COLUMN `key_length` INT AS (CHAR_LENGTH(`key`)) STORED") + "$").
+			WillReturnRows(sqlmock.NewRows([]string{}))
+		mock.ExpectQuery("^" + regexp.QuoteMeta("ALTER TABLE `dummies` ADD COLUMN `key_upper` VARCHAR(50) AS (UPPER(`key`)) VIRTUAL") + "$").
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		err := columnPersistence.CreateSchema(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	// The pool is owned by the caller and is not closed
+	assert.False(t, persistence.IsOpen())
+	assert.Nil(t, persistence.Close(context.Background(), ""))
+	assert.Nil(t, db.Ping())
+}
+
+func TestDummyMySqlPersistenceLeakedCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.debug", true,
+	))
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	persistence.SetClient(db, "test")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `key` FROM `dummies`")).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("Key 1"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `content` FROM `dummies`")).
+		WillReturnRows(sqlmock.NewRows([]string{"content"}).AddRow("Content 1"))
+
+	// The first cursor is closed and the second one is leaked
+	rows, err := persistence.Query(context.Background(), "", "SELECT `key` FROM `dummies`")
+	assert.Nil(t, err)
+	assert.Nil(t, rows.Close())
+	leaked, err := persistence.Query(context.Background(), "", "SELECT `content` FROM `dummies`")
+	assert.Nil(t, err)
+	defer leaked.Close()
+
+	assert.Nil(t, persistence.Close(context.Background(), ""))
+
+	messages := logger.Messages()
+	assert.Len(t, messages, 1)
+	if len(messages) == 1 {
+		assert.Contains(t, messages[0], "SELECT `content` FROM `dummies`")
+		assert.Contains(t, messages[0], "not closed")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDummyMySqlPersistenceBatchPacketSize(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.max_packet_size", 600,
+	))
+	persistence.SetClient(db, "test")
+
+	content := strings.Repeat("x", 100)
+	dummies := []tf.Dummy{
+		{Id: "1", Key: "Key 1", Content: content},
+		{Id: "2", Key: "Key 2", Content: content},
+		{Id: "3", Key: "Key 3", Content: content},
+	}
+	insert := regexp.QuoteMeta("INSERT INTO `dummies` (`id`,`key`,`content`) VALUES ")
+
+	t.Run("DummyMySqlPersistence:CreateBatch", func(t *testing.T) {
+		// Only two rows fit the simulated packet
+		mock.ExpectExec("^"+insert+regexp.QuoteMeta("(?,?,?),(?,?,?)")+"$").
+			WithArgs("1", "Key 1", content, "2", "Key 2", content).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("^"+insert+regexp.QuoteMeta("(?,?,?)")+"$").
+			WithArgs("3", "Key 3", content).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := persistence.CreateBatch(context.Background(), "", dummies)
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:SetBatch", func(t *testing.T) {
+		upsert := regexp.QuoteMeta(" ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`key`=VALUES(`key`),`content`=VALUES(`content`)")
+		mock.ExpectExec("^" + insert + regexp.QuoteMeta("(?,?,?),(?,?,?)") + upsert + "$").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("^" + insert + regexp.QuoteMeta("(?,?,?)") + upsert + "$").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := persistence.SetBatch(context.Background(), "", dummies)
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:CreateBatchNoLimit", func(t *testing.T) {
+		batchPersistence := NewDummyMySqlPersistence()
+		batchPersistence.SetClient(db, "test")
+
+		mock.ExpectExec("^" + insert + regexp.QuoteMeta("(?,?,?),(?,?,?),(?,?,?)") + "$").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		result, err := batchPersistence.CreateBatch(context.Background(), "", dummies)
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyMySqlPersistenceCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	cache := newCaptureCache()
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "cache", "capture", "default", "1.0"), cache,
+	))
+	persistence.SetClient(db, "test")
+
+	columns := []string{"id", "key", "content"}
+
+	t.Run("DummyMySqlPersistence:GetOneByIdCached", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 1"))
+
+		result, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 1", result.Content)
+		assert.Equal(t, 0, cache.Hits())
+
+		// The second read is served from the cache without a query
+		result, err = persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 1", result.Content)
+		assert.Equal(t, 1, cache.Hits())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:UpdateInvalidatesCache", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `dummies` SET ")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 2"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 2"))
+
+		_, err := persistence.Update(context.Background(), "",
+			tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 2"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"dummies:1"}, cache.removals)
+
+		result, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 2", result.Content)
+		assert.Equal(t, 1, cache.Hits())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:DeleteInvalidatesCache", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Key 1", "Content 2"))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := persistence.DeleteById(context.Background(), "", "1")
+		assert.Nil(t, err)
+
+		result, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "", result.Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:DeleteByFilterInvalidatesCache", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("2").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("2", "Key 2", "Content 2"))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `dummies` WHERE `key`=?")).
+			WithArgs("Key 2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("2").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := persistence.GetOneById(context.Background(), "", "2")
+		assert.Nil(t, err)
+
+		// Ids of deleted items are not known, so all cached items of the table are removed
+		err = persistence.DeleteByFilter(context.Background(), "", "`key`=?", "Key 2")
+		assert.Nil(t, err)
+		assert.Contains(t, cache.removals, "dummies:2")
+
+		result, err := persistence.GetOneById(context.Background(), "", "2")
+		assert.Nil(t, err)
+		assert.Equal(t, "", result.Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:ClearInvalidatesCache", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("3").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("3", "Key 3", "Content 3"))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `dummies`")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("3").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := persistence.GetOneById(context.Background(), "", "3")
+		assert.Nil(t, err)
+
+		err = persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Contains(t, cache.removals, "dummies:3")
+
+		result, err := persistence.GetOneById(context.Background(), "", "3")
+		assert.Nil(t, err)
+		assert.Equal(t, "", result.Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyJsonMySqlPersistencePageTotalSqlMock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyJsonMySqlPersistence()
+	persistence.SetClient(db, "test")
+
+	// The count reuses the condition and the parameters of the page
+	where := regexp.QuoteMeta(" WHERE data->>'$.key'=?")
+	mock.ExpectQuery("^" + regexp.QuoteMeta("SELECT * FROM `dummies_json`") + where + " LIMIT 10$").
+		WithArgs("Key 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data"}).
+			AddRow("1", `{"id":"1","key":"Key 0","content":"Content"}`).
+			AddRow("2", `{"id":"2","key":"Key 0","content":"Content"}`))
+	mock.ExpectQuery("^" + regexp.QuoteMeta("SELECT COUNT(*) AS count FROM `dummies_json`") + where + "$").
+		WithArgs("Key 0").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	page, err := persistence.GetPageByFilter(context.Background(), "",
+		*cdata.NewFilterParamsFromTuples("Key", "Key 0"), *cdata.NewPagingParams(0, 10, true))
+	assert.Nil(t, err)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, len(page.Data), page.Total)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDummyJsonMySqlPersistencePageRowErrorSqlMock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyJsonMySqlPersistence()
+	persistence.SetClient(db, "test")
+
+	// A failed page is returned as an error without counting the total
+	where := regexp.QuoteMeta(" WHERE data->>'$.key'=?")
+	mock.ExpectQuery("^" + regexp.QuoteMeta("SELECT * FROM `dummies_json`") + where + " LIMIT 10$").
+		WithArgs("Key 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data"}).
+			AddRow("1", `{"id":"1","key":"Key 0","content":"Content"}`).
+			AddRow("2", `{"id":"2","key":"Key 0","content":"Content"}`).
+			RowError(1, errors.New("connection lost")))
+
+	page, err := persistence.GetPageByFilter(context.Background(), "",
+		*cdata.NewFilterParamsFromTuples("Key", "Key 0"), *cdata.NewPagingParams(0, 10, true))
+	assert.NotNil(t, err)
+	assert.Empty(t, page.Data)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDummyJsonMySqlPersistenceUpdateJsonFieldsSqlMock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	t.Run("DummyJsonMySqlPersistence:UpdateJsonFieldsWithoutReturn", func(t *testing.T) {
+		persistence := NewDummyJsonMySqlPersistence()
+		persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"options.return_on_write", false,
+		))
+		persistence.SetClient(db, "test")
+
+		// The item is not read back, only the id and updated fields are returned
+		mock.ExpectExec("^"+regexp.QuoteMeta("UPDATE `dummies_json` SET `data`=JSON_SET(`data`,?,CAST(? AS JSON),?,CAST(? AS JSON)) WHERE id=?")+"$").
+			WithArgs("$.extra.a", "3", "$.content", `"Content 2"`, "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := persistence.UpdateJsonFields(context.Background(), "", "1",
+			map[string]any{"$.extra.a": 3, "content": "Content 2"})
+		assert.Nil(t, err)
+		assert.Equal(t, tf.Dummy{Id: "1", Content: "Content 2"}, result)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyJsonMySqlPersistence:UpdateJsonFieldsVersion", func(t *testing.T) {
+		persistence := NewDummyJsonMySqlPersistence()
+		persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"options.version_column", "version",
+		))
+		persistence.SetClient(db, "test")
+
+		// The version is checked and incremented instead of being set in the data
+		mock.ExpectExec("^"+regexp.QuoteMeta("UPDATE `dummies_json` SET `data`=JSON_SET(`data`,?,CAST(? AS JSON)),`version`=`version`+1 WHERE id=? AND `version`=?")+"$").
+			WithArgs("$.content", `"Content 3"`, "1", int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, err := persistence.UpdateJsonFields(context.Background(), "123", "1",
+			map[string]any{"content": "Content 3", "version": int64(2)})
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "VERSION_CONFLICT", appErr.Code)
+			assert.Equal(t, "123", appErr.CorrelationId)
+		}
+
+		// Without the version it is only incremented
+		mock.ExpectExec("^"+regexp.QuoteMeta("UPDATE `dummies_json` SET `data`=JSON_SET(`data`,?,CAST(? AS JSON)),`version`=`version`+1 WHERE id=?")+"$").
+			WithArgs("$.content", `"Content 3"`, "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies_json` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "data", "version"}).
+				AddRow("1", `{"id":"1","key":"Key 1","content":"Content 3"}`, 3))
+
+		result, err := persistence.UpdateJsonFields(context.Background(), "", "1",
+			map[string]any{"content": "Content 3"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 3", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyMySqlPersistenceClosedPool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetClient(db, "test")
+
+	// Operations in a pool closed by reconnecting fail with a connection error
+	mock.ExpectClose()
+	assert.Nil(t, db.Close())
+	_, err = persistence.GetOneById(context.Background(), "123", "1")
+	assert.NotNil(t, err)
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, "CONNECT_FAILED", appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+	}
+
+	// Operations after closing the persistence fail with a clean error
+	persistence.SetClient(nil, "test")
+	_, err = persistence.GetOneById(context.Background(), "123", "1")
+	assert.NotNil(t, err)
+	assert.Equal(t, "NOT_OPENED", err.(*cerr.ApplicationError).Code)
+}
+
+func TestDummyMySqlPersistenceUnserializablePartial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetClient(db, "test")
+	jsonPersistence := NewDummyJsonMySqlPersistence()
+	jsonPersistence.SetClient(db, "test")
+
+	// Partial updates with values that can't be serialized are rejected before any query
+	data := *cdata.NewAnyValueMapFromTuples("content", "New Content", "extra", map[string]any{"callback": func() {}})
+
+	_, err = persistence.UpdatePartially(context.Background(), "123", "1", data)
+	assert.NotNil(t, err)
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, cerr.BadRequest, appErr.Category)
+		assert.Equal(t, "UNSERIALIZABLE_FIELD", appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+		assert.Equal(t, "extra.callback", appErr.Details["field"])
+		assert.Contains(t, appErr.Message, "extra.callback")
+	}
+
+	_, err = jsonPersistence.UpdatePartially(context.Background(), "123", "1",
+		*cdata.NewAnyValueMapFromTuples("content", make(chan int)))
+	assert.NotNil(t, err)
+	appErr, ok = err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, "UNSERIALIZABLE_FIELD", appErr.Code)
+		assert.Equal(t, "content", appErr.Details["field"])
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDummyMySqlPersistenceProtectedColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.protected_columns", "id, key",
+		"options.return_on_write", false,
+	))
+	persistence.SetClient(db, "test")
+	data := *cdata.NewAnyValueMapFromTuples("key", "Key 2", "content", "Content 2")
+
+	t.Run("DummyMySqlPersistence:ProtectedColumnError", func(t *testing.T) {
+		_, err := persistence.UpdatePartially(context.Background(), "123", "1", data)
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "PROTECTED_COLUMN", appErr.Code)
+			assert.Equal(t, "key", appErr.Details["column"])
+		}
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:ProtectedColumnDropped", func(t *testing.T) {
+		persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"options.protected_columns", "id, key",
+			"options.drop_protected_columns", true,
+			"options.return_on_write", false,
+		))
+
+		// Only the allowed column is updated
+		mock.ExpectExec("^"+regexp.QuoteMeta("UPDATE `dummies` SET `content`=? WHERE id=?")+"$").
+			WithArgs("Content 2", "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := persistence.UpdatePartially(context.Background(), "", "1", data)
+		assert.Nil(t, err)
+		assert.Equal(t, "1", result.Id)
+		assert.Equal(t, "", result.Key)
+		assert.Equal(t, "Content 2", result.Content)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyMySqlPersistenceUpdateWithResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetClient(db, "test")
+	dummy := tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"}
+	update := regexp.QuoteMeta("UPDATE `dummies` SET ") + ".+" + regexp.QuoteMeta(" WHERE id=?")
+
+	t.Run("DummyMySqlPersistence:UpdateMissing", func(t *testing.T) {
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "key", "content"}))
+
+		_, updated, err := persistence.UpdateWithResult(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.return_on_write", false,
+	))
+
+	t.Run("DummyMySqlPersistence:UpdateMissingNoReturn", func(t *testing.T) {
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+		_, updated, err := persistence.UpdateWithResult(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMySqlPersistence:UpdateUnchanged", func(t *testing.T) {
+		// The server reports no changed rows for the same values
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM `dummies` WHERE id=?")).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		result, updated, err := persistence.UpdateWithResult(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+		assert.Equal(t, dummy, result)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyMapMySqlPersistenceBatchIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMapMySqlPersistence()
+	persistence.SetClient(db, "test")
+
+	// Items with preset, empty, nil and missing ids
+	dummies := []map[string]any{
+		{"id": "1", "key": "Key 1", "content": "Content 1"},
+		{"id": "", "key": "Key 2", "content": "Content 2"},
+		{"id": nil, "key": "Key 3", "content": "Content 3"},
+		{"key": "Key 4", "content": "Content 4"},
+	}
+	insert := regexp.QuoteMeta("INSERT INTO `dummies` (`content`,`id`,`key`) VALUES (?,?,?),(?,?,?),(?,?,?),(?,?,?)")
+	args := []driver.Value{
+		"Content 1", "1", "Key 1",
+		"Content 2", sqlmock.AnyArg(), "Key 2",
+		"Content 3", sqlmock.AnyArg(), "Key 3",
+		"Content 4", sqlmock.AnyArg(), "Key 4",
+	}
+
+	checkIds := func(t *testing.T, result []map[string]any) {
+		assert.Len(t, result, 4)
+		assert.Equal(t, "1", result[0]["id"])
+		ids := map[string]bool{}
+		for _, item := range result {
+			id, ok := item["id"].(string)
+			assert.True(t, ok)
+			assert.NotEqual(t, "", id)
+			ids[id] = true
+		}
+		assert.Len(t, ids, 4)
+		// The given items are not changed
+		_, ok := dummies[3]["id"]
+		assert.False(t, ok)
+	}
+
+	t.Run("DummyMapMySqlPersistence:CreateBatch", func(t *testing.T) {
+		mock.ExpectExec("^" + insert + "$").
+			WithArgs(args...).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		result, err := persistence.CreateBatch(context.Background(), "", dummies)
+		assert.Nil(t, err)
+		checkIds(t, result)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMapMySqlPersistence:SetBatch", func(t *testing.T) {
+		upsert := regexp.QuoteMeta(" ON DUPLICATE KEY UPDATE `content`=VALUES(`content`),`id`=VALUES(`id`),`key`=VALUES(`key`)")
+		mock.ExpectExec("^" + insert + upsert + "$").
+			WithArgs(args...).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		result, err := persistence.SetBatch(context.Background(), "", dummies)
+		assert.Nil(t, err)
+		checkIds(t, result)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDummyMapMySqlPersistenceUnknownColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummyMapMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.unknown_columns", "drop",
+	))
+	persistence.SetClient(db, "test")
+	dummy := map[string]any{"id": "1", "key": "Key 1", "content": "Content 1", "extra": "Extra 1"}
+
+	t.Run("DummyMapMySqlPersistence:SkipUnreadColumns", func(t *testing.T) {
+		// The check is skipped when columns of the table can't be read
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COLUMN_NAME, DATA_TYPE FROM information_schema.COLUMNS WHERE ")).
+			WillReturnError(errors.New("connection refused"))
+		mock.ExpectQuery("^"+regexp.QuoteMeta("INSERT INTO `dummies` (`content`,`extra`,`id`,`key`) VALUES (?,?,?,?)")+"$").
+			WithArgs("Content 1", "Extra 1", "1", "Key 1").
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		_, err := persistence.Create(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMapMySqlPersistence:DropUnknownColumns", func(t *testing.T) {
+		// Columns of the table are read again after the failure and kept for next writes
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COLUMN_NAME, DATA_TYPE FROM information_schema.COLUMNS WHERE ")).
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).
+				AddRow("id", "varchar").
+				AddRow("key", "varchar").
+				AddRow("content", "text"))
+		mock.ExpectQuery("^"+regexp.QuoteMeta("INSERT INTO `dummies` (`content`,`id`,`key`) VALUES (?,?,?)")+"$").
+			WithArgs("Content 1", "1", "Key 1").
+			WillReturnRows(sqlmock.NewRows([]string{}))
+
+		_, err := persistence.Create(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DummyMapMySqlPersistence:RejectUnknownColumns", func(t *testing.T) {
+		persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+			"options.unknown_columns", "error",
+		))
+
+		_, err := persistence.Create(context.Background(), "123", dummy)
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, cerr.BadRequest, appErr.Category)
+			assert.Equal(t, "UNKNOWN_COLUMNS", appErr.Code)
+			assert.Equal(t, "123", appErr.CorrelationId)
+			assert.Contains(t, appErr.Message, "extra")
+		}
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,11 +2,23 @@ package test
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	cref "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
 	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDummyMySqlPersistence(t *testing.T) {
@@ -91,5 +103,1602 @@ func TestDummyMySqlPersistence(t *testing.T) {
 		return
 	}
 
+	t.Run("DummyMySqlPersistence:Count", fixture.TestCountOperation)
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
 	t.Run("DummyMySqlPersistence:Random", fixture.TestRandomOperation)
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:PageWithIndex", func(t *testing.T) {
+		dummy1, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		dummy2, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 2", Content: "Content 2"})
+		assert.Nil(t, err)
+
+		page, index, err := persist.GetPageByFilterWithIndex[tf.Dummy, string](context.Background(), "",
+			persistence.IdentifiableMySqlPersistence, "", *cdata.NewEmptyPagingParams(), "", "")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 2)
+		assert.Len(t, index, 2)
+
+		for _, item := range page.Data {
+			assert.Equal(t, item, index[item.Id])
+		}
+		assert.Equal(t, dummy1.Key, index[dummy1.Id].Key)
+		assert.Equal(t, dummy2.Key, index[dummy2.Id].Key)
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:LikeFilter", func(t *testing.T) {
+		for _, content := range []string{"100% done", "1000 done", "a_b", "axb", "a\\b"} {
+			_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: content, Content: content})
+			assert.Nil(t, err)
+		}
+
+		filter, param := persistence.GenerateLikeFilter("content", "0%")
+		assert.Equal(t, "`content` LIKE ? ESCAPE '!'", filter)
+		assert.Equal(t, "%0!%%", param)
+
+		var count int64
+		query := "SELECT COUNT(*) FROM " + persistence.QuotedTableName() + " WHERE " + filter
+		err := persistence.Client.QueryRowContext(context.Background(), query, param).Scan(&count)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		filter, param = persistence.GenerateLikeFilter("content", "a_")
+		assert.Equal(t, "%a!_%", param)
+
+		err = persistence.Client.QueryRowContext(context.Background(), query, param).Scan(&count)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("DummyMySqlPersistence:Timeout", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 3", Content: "Content 3"})
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err = persistence.IdentifiableMySqlPersistence.GetPageByFilter(ctx, "123",
+			"SLEEP(2)=0", *cdata.NewEmptyPagingParams(), "", "")
+		assert.NotNil(t, err)
+		assert.True(t, persist.IsTimeoutError(err))
+
+		appErr := err.(*cerr.ApplicationError)
+		assert.Equal(t, persist.TimeoutErrorCode, appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+		assert.Equal(t, "get_page", appErr.Details["operation"])
+		assert.Equal(t, "dummies", appErr.Details["table"])
+	})
+
+	t.Run("DummyMySqlPersistence:OperationTimeout", func(t *testing.T) {
+		timeoutPersistence := NewDummyMySqlPersistence()
+		timeoutPersistence.Configure(context.Background(), dbConfig.Override(
+			cconf.NewConfigParamsFromTuples("options.operation_timeout_ms", 100),
+		))
+		err := timeoutPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer timeoutPersistence.Close(context.Background(), "")
+
+		// Operation is limited without a deadline in the caller's context
+		_, err = timeoutPersistence.IdentifiableMySqlPersistence.GetPageByFilter(context.Background(), "123",
+			"SLEEP(2)=0", *cdata.NewEmptyPagingParams(), "", "")
+		assert.NotNil(t, err)
+		assert.True(t, persist.IsTimeoutError(err))
+
+		// Fast operations are not affected
+		_, err = timeoutPersistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", "")
+		assert.Nil(t, err)
+	})
+
+	t.Run("DummyMySqlPersistence:GetOneByIdError", func(t *testing.T) {
+		missing := persistence.WithTable("dummies_missing")
+		err := missing.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer missing.Close(context.Background(), "")
+
+		_, err = missing.GetOneById(context.Background(), "123", "1")
+		assert.NotNil(t, err)
+
+		// The missing table is not a connectivity failure
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "QUERY_FAILED", appErr.Code)
+			assert.Equal(t, "123", appErr.CorrelationId)
+		}
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:Cursor", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			id := "id_" + strconv.Itoa(10+i)
+			_, err := persistence.Create(context.Background(), "", tf.Dummy{Id: id, Key: "Key " + id, Content: "Content"})
+			assert.Nil(t, err)
+		}
+
+		// Insert items while paging through the table
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				id := "id_" + strconv.Itoa(i) + "x"
+				_, err := persistence.Create(context.Background(), "", tf.Dummy{Id: id, Key: "Key " + id, Content: "Content"})
+				assert.Nil(t, err)
+			}
+		}()
+
+		seen := make(map[string]int)
+		var cursor any
+		for {
+			items, next, err := persistence.IdentifiableMySqlPersistence.GetPageByCursor(context.Background(), "",
+				"", "id", cursor, 3)
+			assert.Nil(t, err)
+			assert.LessOrEqual(t, len(items), 3)
+			for _, item := range items {
+				seen[item.Id]++
+			}
+			if err != nil || next == nil {
+				break
+			}
+			cursor = next
+		}
+		wg.Wait()
+
+		for id, count := range seen {
+			assert.Equal(t, 1, count, "Item %s is returned more than once", id)
+		}
+		for i := 0; i < 10; i++ {
+			assert.Contains(t, seen, "id_"+strconv.Itoa(10+i))
+		}
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:Collation", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 11", Content: "Content 1"})
+		assert.Nil(t, err)
+
+		filter, param := persistence.GenerateCollatedFilter("key", "key 11", "utf8mb4_general_ci")
+		assert.Equal(t, "`key`=? COLLATE utf8mb4_general_ci", filter)
+
+		items, err := persistence.GetListByFilter(context.Background(), "", filter, "", "", param)
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+
+		filter, param = persistence.GenerateCollatedFilter("key", "key 11", "utf8mb4_bin")
+		items, err = persistence.GetListByFilter(context.Background(), "", filter, "", "", param)
+		assert.Nil(t, err)
+		assert.Len(t, items, 0)
+
+		// Unsafe characters are removed from collation names
+		filter, _ = persistence.GenerateCollatedFilter("key", "key 11", "utf8mb4_bin; DROP TABLE dummies")
+		assert.Equal(t, "`key`=? COLLATE utf8mb4_binDROPTABLEdummies", filter)
+	})
+
+	t.Run("DummyMySqlPersistence:StrictInsert", func(t *testing.T) {
+		strictPersistence := NewDummyMySqlPersistence()
+		strictPersistence.Configure(context.Background(), dbConfig.Override(
+			cconf.NewConfigParamsFromTuples("options.strict_insert", true),
+		))
+		err := strictPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer strictPersistence.Close(context.Background(), "")
+
+		dummy := tf.Dummy{Id: "strict_1", Key: "Strict key", Content: "Content 1"}
+		result, err := strictPersistence.Set(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy, result)
+
+		dummy.Content = "Content 2"
+		_, err = strictPersistence.Set(context.Background(), "123", dummy)
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "DUPLICATE_KEY", appErr.Code)
+			assert.Equal(t, "123", appErr.CorrelationId)
+		}
+
+		// The existing item is not changed
+		result, err = strictPersistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 1", result.Content)
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:ExistsFilter", func(t *testing.T) {
+		_, err := persistence.Client.ExecContext(context.Background(),
+			"CREATE TABLE IF NOT EXISTS `dummies_tags` (`dummy_id` VARCHAR(32), `tag` VARCHAR(50))")
+		assert.Nil(t, err)
+		defer persistence.Client.ExecContext(context.Background(), "DROP TABLE `dummies_tags`")
+
+		_, err = persistence.Client.ExecContext(context.Background(), "DELETE FROM `dummies_tags`")
+		assert.Nil(t, err)
+
+		tags := map[string][]string{"1": {"red", "green"}, "2": {"green"}, "3": nil}
+		for id, dummyTags := range tags {
+			_, err = persistence.Create(context.Background(), "", tf.Dummy{Id: id, Key: "Key " + id, Content: "Content"})
+			assert.Nil(t, err)
+			for _, tag := range dummyTags {
+				_, err = persistence.Client.ExecContext(context.Background(),
+					"INSERT INTO `dummies_tags` (`dummy_id`, `tag`) VALUES (?, ?)", id, tag)
+				assert.Nil(t, err)
+			}
+		}
+
+		filter, args := persistence.GenerateExistsFilter("dummies_tags", "dummy_id", "`tag`=?", "red")
+		assert.Equal(t, "EXISTS (SELECT 1 FROM `dummies_tags` WHERE `dummies_tags`.`dummy_id`=`dummies`.`id` AND (`tag`=?))", filter)
+
+		items, err := persistence.GetListByFilter(context.Background(), "", filter, "", "", args...)
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		if len(items) == 1 {
+			assert.Equal(t, "1", items[0].Id)
+		}
+
+		// Items with any related rows
+		filter, args = persistence.GenerateExistsFilter("dummies_tags", "dummy_id", "")
+		count, err := persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", filter, args...)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("DummyMySqlPersistence:Upsert", func(t *testing.T) {
+		assert.Equal(t, "`key`=VALUES(`key`),`content`=VALUES(`content`)",
+			persistence.GenerateUpsertParameters([]string{"key", "content"}))
+
+		_, err := persistence.Create(context.Background(), "", tf.Dummy{Id: "upsert_1", Key: "Key A", Content: "Content A"})
+		assert.Nil(t, err)
+		_, err = persistence.Create(context.Background(), "", tf.Dummy{Id: "upsert_2", Key: "Key C", Content: "Content C"})
+		assert.Nil(t, err)
+
+		result, err := persistence.Set(context.Background(), "", tf.Dummy{Id: "upsert_1", Key: "Key B", Content: "Content B"})
+		assert.Nil(t, err)
+		assert.Equal(t, tf.Dummy{Id: "upsert_1", Key: "Key B", Content: "Content B"}, result)
+
+		result, err = persistence.GetOneById(context.Background(), "", "upsert_1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Key B", result.Key)
+		assert.Equal(t, "Content B", result.Content)
+
+		// Other items are not touched
+		result, err = persistence.GetOneById(context.Background(), "", "upsert_2")
+		assert.Nil(t, err)
+		assert.Equal(t, "Key C", result.Key)
+		assert.Equal(t, "Content C", result.Content)
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyMySqlPersistence:Distinct", func(t *testing.T) {
+		for i, content := range []string{"Same content", "Same content", "Other content"} {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Distinct key " + strconv.Itoa(i), Content: content})
+			assert.Nil(t, err)
+		}
+
+		page, err := persistence.IdentifiableMySqlPersistence.GetPageByFilter(context.Background(), "",
+			"", *cdata.NewPagingParams(0, 10, true), "`content`", "`content`")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 3)
+		assert.Equal(t, 3, page.Total)
+
+		// Rows with repeated content are deduplicated and counted once
+		page, err = persistence.GetDistinctPageByFilter(context.Background(), "",
+			"", *cdata.NewPagingParams(0, 10, true), "`content`", "`content`")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 2)
+		assert.Equal(t, 2, page.Total)
+		assert.Equal(t, "Other content", page.Data[0].Content)
+		assert.Equal(t, "Same content", page.Data[1].Content)
+
+		// Repeated columns are selected once
+		page, err = persistence.GetDistinctPageByFilter(context.Background(), "",
+			"", *cdata.NewPagingParams(0, 10, true), "`content`", "`content`, content, `CONTENT`")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 2)
+		assert.Equal(t, 2, page.Total)
+
+		list, err := persistence.GetDistinctListByFilter(context.Background(), "",
+			"`content` LIKE ?", "", "`content`", "Same%")
+		assert.Nil(t, err)
+		assert.Len(t, list, 1)
+		assert.Equal(t, "Same content", list[0].Content)
+	})
+
+	t.Run("DummyMySqlPersistence:MapPage", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i, content := range []string{"Content", "Longer content"} {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Map key " + strconv.Itoa(i), Content: content})
+			assert.Nil(t, err)
+		}
+
+		// Computed columns are returned as is without the data type
+		page, err := persistence.GetMapPageByFilter(context.Background(), "",
+			"`key` LIKE ?", *cdata.NewPagingParams(0, 10, true),
+			"`key`", "`key`, CHAR_LENGTH(`content`) AS content_length", "Map key%")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, page.Total)
+		assert.Len(t, page.Data, 2)
+		assert.Equal(t, map[string]any{"key": "Map key 0", "content_length": json.Number("7")}, page.Data[0])
+		assert.Equal(t, map[string]any{"key": "Map key 1", "content_length": json.Number("14")}, page.Data[1])
+	})
+
+	t.Run("DummyMySqlPersistence:CountAll", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Count key " + strconv.Itoa(i), Content: "Content"})
+			assert.Nil(t, err)
+		}
+
+		count, err := persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+
+		approximatePersistence := NewDummyMySqlPersistence()
+		approximatePersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"options.approximate_count", true,
+		)))
+		err = approximatePersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer approximatePersistence.Close(context.Background(), "")
+
+		// Statistics are refreshed, so the estimate is stable while the table is not changed
+		_, err = persistence.Client.ExecContext(context.Background(), "ANALYZE TABLE "+persistence.QuotedTableName())
+		assert.Nil(t, err)
+
+		var tableRows int64
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=?",
+			persistence.TableName).Scan(&tableRows)
+		assert.Nil(t, err)
+
+		count, err = approximatePersistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, tableRows, count)
+	})
+
+	t.Run("DummyMySqlPersistence:RawTableName", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for _, content := range []string{"Visible content", "Hidden content"} {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "View " + content, Content: content})
+			assert.Nil(t, err)
+		}
+
+		_, err = persistence.Client.ExecContext(context.Background(),
+			"CREATE OR REPLACE VIEW `dummies_visible` AS SELECT * FROM `dummies` WHERE `content` LIKE 'Visible%'")
+		assert.Nil(t, err)
+		defer persistence.Client.ExecContext(context.Background(), "DROP VIEW IF EXISTS `dummies_visible`")
+
+		// The schema is checked by the table name while queries read the view
+		viewPersistence := NewDummyMySqlPersistence()
+		viewPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"raw_table", "`"+mysqlDatabase+"`.`dummies_visible`",
+		)))
+		err = viewPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer viewPersistence.Close(context.Background(), "")
+
+		items, err := viewPersistence.GetAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, "Visible content", items[0].Content)
+
+		// DDL statements change the table instead of the view
+		err = viewPersistence.Truncate(context.Background(), "")
+		assert.Nil(t, err)
+
+		count, err := persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("DummyMySqlPersistence:ExecuteQuery", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Query key " + strconv.Itoa(i), Content: "Content " + strconv.Itoa(i)})
+			assert.Nil(t, err)
+		}
+
+		result, err := persistence.ExecuteQuery(context.Background(), "",
+			"UPDATE `dummies` SET `content`=@content WHERE `key`=@first OR `key`=@last",
+			map[string]any{"first": "Query key 0", "last": "Query key 2", "content": "Updated"})
+		assert.Nil(t, err)
+		affected, err := result.RowsAffected()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), affected)
+
+		count, err := persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "",
+			"`content`=?", "Updated")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("DummyMySqlPersistence:DeleteAll", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Delete key", Content: "Content"})
+		assert.Nil(t, err)
+
+		// Nothing is deleted without confirmation
+		err = persistence.DeleteAll(context.Background(), "", false)
+		assert.NotNil(t, err)
+		assert.Equal(t, "DELETE_NOT_CONFIRMED", err.(*cerr.ApplicationError).Code)
+
+		count, err := persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.NotEqual(t, int64(0), count)
+
+		err = persistence.DeleteAll(context.Background(), "", true)
+		assert.Nil(t, err)
+
+		count, err = persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("DummyMySqlPersistence:GetAll", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "All key " + strconv.Itoa(i), Content: "Content"})
+			assert.Nil(t, err)
+		}
+
+		items, err := persistence.GetAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Len(t, items, 5)
+
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			keys = append(keys, item.Key)
+		}
+		for i := 0; i < 5; i++ {
+			assert.Contains(t, keys, "All key "+strconv.Itoa(i))
+		}
+
+		// Canceled context stops reading
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = persistence.GetAll(ctx, "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("DummyMySqlPersistence:GetFirst", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 1; i <= 3; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "First key " + strconv.Itoa(i), Content: "Content " + strconv.Itoa(i%2)})
+			assert.Nil(t, err)
+		}
+
+		// Latest
+		item, ok, err := persistence.GetFirstByFilter(context.Background(), "", "", "`key` DESC")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "First key 3", item.Key)
+
+		// Earliest
+		item, ok, err = persistence.GetFirstByFilter(context.Background(), "", "", "`key` ASC")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "First key 1", item.Key)
+
+		// Filtered
+		item, ok, err = persistence.GetFirstByFilter(context.Background(), "",
+			"content=?", "`key` DESC", "Content 0")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "First key 2", item.Key)
+
+		// Nothing found
+		_, ok, err = persistence.GetFirstByFilter(context.Background(), "",
+			"content=?", "`key` DESC", "Missing")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("DummyMySqlPersistence:DeleteBatched", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 0; i < 50; i++ {
+			content := "Keep"
+			if i < 45 {
+				content = "Delete"
+			}
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Batch key " + strconv.Itoa(i), Content: content})
+			assert.Nil(t, err)
+		}
+
+		deleted, err := persistence.DeleteByFilterBatched(context.Background(), "", "content=?", 4, "Delete")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(45), deleted)
+
+		count, err := persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), count)
+
+		// Canceled context stops deleting
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		deleted, err = persistence.DeleteByFilterBatched(ctx, "", "", 2)
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		_, err = persistence.DeleteByFilterBatched(context.Background(), "", "", 0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("DummyMySqlPersistence:DistinctValues", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		for i := 0; i < 6; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Values key " + strconv.Itoa(i), Content: "Content " + strconv.Itoa(i%3)})
+			assert.Nil(t, err)
+		}
+
+		values, err := persistence.GetDistinctValues(context.Background(), "", "content", "")
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []any{"Content 0", "Content 1", "Content 2"}, values)
+
+		values, err = persistence.GetDistinctValues(context.Background(), "", "key", "content=?", "Content 1")
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []any{"Values key 1", "Values key 4"}, values)
+	})
+
+	t.Run("DummyMySqlPersistence:Clear", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := persistence.Create(context.Background(), "",
+				tf.Dummy{Key: "Clear key " + strconv.Itoa(i), Content: "Content"})
+			assert.Nil(t, err)
+		}
+
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		count, err := persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		// No connection is left busy with an open cursor
+		assert.Equal(t, 0, persistence.Client.Stats().InUse)
+	})
+
+	t.Run("DummyMySqlPersistence:Schemas", func(t *testing.T) {
+		tenantSchema := mysqlDatabase + "_tenant"
+		_, err := persistence.Client.ExecContext(context.Background(), "CREATE SCHEMA IF NOT EXISTS `"+tenantSchema+"`")
+		assert.Nil(t, err)
+		defer persistence.Client.ExecContext(context.Background(), "DROP SCHEMA IF EXISTS `"+tenantSchema+"`")
+
+		_, err = persistence.Client.ExecContext(context.Background(),
+			"CREATE TABLE IF NOT EXISTS "+persistence.QuotedTableNameFor(tenantSchema, persistence.TableName)+
+				" LIKE "+persistence.QuotedTableName())
+		assert.Nil(t, err)
+
+		tenant := persistence.WithSchema(tenantSchema)
+		err = tenant.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer tenant.Close(context.Background(), "")
+
+		dummy, err := tenant.Create(context.Background(), "", tf.Dummy{Key: "Tenant key", Content: "Tenant content"})
+		assert.Nil(t, err)
+
+		// Item is visible only in the tenant schema
+		result, err := tenant.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy.Key, result.Key)
+
+		result, err = persistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, tf.Dummy{}, result)
+	})
+
+	t.Run("DummyMySqlPersistence:Reopen", func(t *testing.T) {
+		err := persistence.Close(context.Background(), "")
+		assert.Nil(t, err)
+
+		// Second open must skip already existing table and indexes
+		err = persistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+
+		indexes, err := persistence.GetIndexes(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Contains(t, indexes, persistence.TableName+"_key")
+	})
+}
+
+func TestDummyMySqlPersistenceCorrelationId(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.CorrelationIdGenerator = func(ctx context.Context) string {
+		return "generated_id"
+	}
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+
+	assert.NotEmpty(t, logger.correlationIds)
+	for _, correlationId := range logger.correlationIds {
+		assert.Equal(t, "generated_id", correlationId)
+	}
+}
+
+func TestDummyMySqlPersistenceCorrelationIdContext(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.log_queries", true,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Create(context.Background(), "123", tf.Dummy{Key: "Context key", Content: "Content"})
+	assert.Nil(t, err)
+	_, err = persistence.GetCountByFilter(context.Background(), "123", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	err = persistence.Clear(context.Background(), "123")
+	assert.Nil(t, err)
+
+	// The correlationId is retrievable from contexts passed to the logger
+	contextIds := logger.ContextIds()
+	assert.NotEmpty(t, contextIds)
+	for _, correlationId := range contextIds {
+		assert.Equal(t, "123", correlationId)
+	}
+
+	ctx := persist.ContextWithCorrelationId(context.Background(), "456")
+	correlationId, ok := persist.GetCorrelationIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "456", correlationId)
+
+	_, ok = persist.GetCorrelationIdFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestDummyMySqlPersistencePoolSize(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	t.Run("DummyMySqlPersistence:DefaultPoolSize", func(t *testing.T) {
+		persistence := NewDummyMySqlPersistence()
+		persistence.Configure(context.Background(), dbConfig)
+
+		err := persistence.Open(context.Background(), "")
+		if err != nil {
+			t.Error("Error opened persistence", err)
+			return
+		}
+		defer persistence.Close(context.Background(), "")
+
+		// The local connection takes the persistence default instead of its own
+		assert.Equal(t, 2, persistence.Client.Stats().MaxOpenConnections)
+	})
+
+	t.Run("DummyMySqlPersistence:UnconfiguredPoolSize", func(t *testing.T) {
+		persistence := NewDummyMySqlPersistence()
+
+		// Without connection parameters the open fails, but the local connection is already configured
+		err := persistence.Open(context.Background(), "")
+		assert.NotNil(t, err)
+		assert.NotNil(t, persistence.Connection)
+		assert.Equal(t, 2, persistence.Connection.Options.GetAsInteger("max_pool_size"))
+		assert.Equal(t, 5000, persistence.Connection.Options.GetAsInteger("connect_timeout"))
+	})
+
+	t.Run("DummyMySqlPersistence:ConfiguredPoolSize", func(t *testing.T) {
+		persistence := NewDummyMySqlPersistence()
+		persistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"options.max_pool_size", 5,
+		)))
+
+		err := persistence.Open(context.Background(), "")
+		if err != nil {
+			t.Error("Error opened persistence", err)
+			return
+		}
+		defer persistence.Close(context.Background(), "")
+
+		assert.Equal(t, 5, persistence.Client.Stats().MaxOpenConnections)
+	})
+}
+
+func TestDummyMySqlPersistenceNotOpened(t *testing.T) {
+	persistence := NewDummyMySqlPersistence()
+
+	_, err := persistence.GetOneById(context.Background(), "", "1")
+	assert.NotNil(t, err)
+	assert.Equal(t, "NOT_OPENED", err.(*cerr.ApplicationError).Code)
+}
+
+func TestDummyMySqlPersistenceEffectivePaging(t *testing.T) {
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.max_page_size", 50,
+	))
+
+	skip, take := persistence.GetEffectivePaging(*cdata.NewPagingParams(10, 1000, false))
+	assert.Equal(t, int64(10), skip)
+	assert.Equal(t, int64(50), take)
+
+	skip, take = persistence.GetEffectivePaging(*cdata.NewEmptyPagingParams())
+	assert.Equal(t, int64(0), skip)
+	assert.Equal(t, int64(50), take)
+
+	// Unset take uses the max page size without the default one
+	_, take = persistence.GetEffectivePaging(*cdata.NewPagingParams(0, 0, false))
+	assert.Equal(t, int64(50), take)
+
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.default_page_size", 20,
+		"options.max_page_size", 1000,
+	))
+
+	// Unset take uses the default page size
+	_, take = persistence.GetEffectivePaging(*cdata.NewPagingParams(0, 0, false))
+	assert.Equal(t, int64(20), take)
+
+	_, take = persistence.GetEffectivePaging(*cdata.NewPagingParams(0, -1, false))
+	assert.Equal(t, int64(20), take)
+
+	// Set take is applied up to the max page size
+	_, take = persistence.GetEffectivePaging(*cdata.NewPagingParams(0, 500, false))
+	assert.Equal(t, int64(500), take)
+
+	_, take = persistence.GetEffectivePaging(*cdata.NewPagingParams(0, 5000, false))
+	assert.Equal(t, int64(1000), take)
+}
+
+func TestDummyMySqlPersistenceQuoteIdentifier(t *testing.T) {
+	persistence := NewDummyMySqlPersistence()
+
+	assert.Equal(t, "`dummies`", persistence.QuoteIdentifier("dummies"))
+	assert.Equal(t, "`dummies`", persistence.QuoteIdentifier("`dummies`"))
+	assert.Equal(t, "`dum``mies`", persistence.QuoteIdentifier("`dum``mies`"))
+
+	// Embedded backticks are doubled
+	assert.Equal(t, "`dum``mies`", persistence.QuoteIdentifier("dum`mies"))
+	assert.Equal(t, "```; DROP TABLE dummies; --`", persistence.QuoteIdentifier("`; DROP TABLE dummies; --"))
+	assert.Equal(t, "```x``; DROP TABLE dummies; ```", persistence.QuoteIdentifier("`x`; DROP TABLE dummies; `"))
+	assert.Equal(t, "````", persistence.QuoteIdentifier("`"))
+
+	assert.Equal(t, "", persistence.QuoteIdentifier(""))
+	assert.Equal(t, "", persistence.QuoteIdentifier("  "))
+
+	assert.Equal(t, "`test`.`dum``mies`", persistence.QuotedTableNameFor("test", "dum`mies"))
+
+	// Blank table names are rejected on opening
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"table", "  ",
+	))
+	err := persistence.Open(context.Background(), "")
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_IDENTIFIER", err.(*cerr.ApplicationError).Code)
+
+	// ANSI double quotes
+	persistence = NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.identifier_quote", "ansi",
+	))
+	assert.Equal(t, "\"dummies\"", persistence.QuoteIdentifier("dummies"))
+	assert.Equal(t, "\"dummies\"", persistence.QuoteIdentifier("\"dummies\""))
+	assert.Equal(t, "\"dum\"\"mies\"", persistence.QuoteIdentifier("dum\"mies"))
+	assert.Equal(t, "\"dum`mies\"", persistence.QuoteIdentifier("dum`mies"))
+	assert.Equal(t, "\"test\".\"dummies\"", persistence.QuotedTableNameFor("test", "dummies"))
+
+	// Unknown quoting styles are rejected on opening
+	persistence = NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.identifier_quote", "brackets",
+	))
+	err = persistence.Open(context.Background(), "")
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_IDENTIFIER_QUOTE", err.(*cerr.ApplicationError).Code)
+}
+
+func TestDummyMySqlPersistenceAnsiQuotes(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.sql_mode", "ANSI_QUOTES",
+		"options.identifier_quote", "ansi",
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	fixture := tf.NewDummyPersistenceFixture(persistence)
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyMySqlPersistence:CRUD", fixture.TestCrudOperations)
+
+	t.Run("DummyMySqlPersistence:Filter", func(t *testing.T) {
+		err := persistence.Clear(context.Background(), "")
+		assert.Nil(t, err)
+
+		_, err = persistence.Create(context.Background(), "", tf.Dummy{Key: "Ansi key", Content: "Content"})
+		assert.Nil(t, err)
+
+		filter, value := persistence.GenerateEqualFilter("key", "Ansi key")
+		items, err := persistence.GetListByFilter(context.Background(), "", filter, "\"key\"", "", value)
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+
+		// Columns in double quotes are repeated columns
+		page, err := persistence.GetDistinctPageByFilter(context.Background(), "",
+			"", *cdata.NewPagingParams(0, 10, true), "\"content\"", "\"content\", content, `CONTENT`")
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+		assert.Equal(t, 1, page.Total)
+	})
+}
+
+func TestDummyMySqlPersistenceQuotedTable(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"table", "dum'mies`quoted",
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+	defer persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS "+persistence.QuotedTableName())
+
+	// The existing table is found on reopening, so it is not created again
+	err := persistence.Close(context.Background(), "")
+	assert.Nil(t, err)
+	err = persistence.Open(context.Background(), "")
+	assert.Nil(t, err)
+
+	indexes, err := persistence.GetIndexes(context.Background(), "")
+	assert.Nil(t, err)
+	assert.Contains(t, indexes, "dum'mies`quoted_key")
+}
+
+func TestDummyMySqlPersistenceColumnOrder(t *testing.T) {
+	persistence := NewDummyMySqlPersistence()
+
+	objMap, err := persistence.ConvertFromPublic(tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+	objMap["extra"] = "Extra 1"
+
+	// Columns follow the declaration order of the struct fields, unknown ones are placed last
+	for i := 0; i < 10; i++ {
+		columns, values := persistence.GenerateColumnsAndValues(objMap)
+		assert.Equal(t, []string{"id", "key", "content", "extra"}, columns)
+		assert.Equal(t, []any{"1", "Key 1", "Content 1", "Extra 1"}, values)
+	}
+
+	// Columns of maps are sorted by names
+	mapPersistence := NewDummyMapMySqlPersistence()
+	for i := 0; i < 10; i++ {
+		columns, values := mapPersistence.GenerateColumnsAndValues(map[string]any{"key": "Key 1", "content": "Content 1", "id": "1"})
+		assert.Equal(t, []string{"content", "id", "key"}, columns)
+		assert.Equal(t, []any{"Content 1", "1", "Key 1"}, values)
+	}
+}
+
+func TestDummyMySqlPersistenceParamsRedaction(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.log_params", true,
+		"options.redact_columns", "content",
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Visible key", Content: "Secret content"})
+	assert.Nil(t, err)
+
+	logged := strings.Join(logger.Messages(), "\n")
+	assert.Contains(t, logged, "Visible key")
+	assert.Contains(t, logged, persist.RedactedValue)
+	assert.NotContains(t, logged, "Secret content")
+}
+
+func TestDummyMySqlPersistenceCounters(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	counters := newCaptureCounters()
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "counters", "capture", "default", "1.0"), counters,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.GetPageByFilter(context.Background(), "", *cdata.NewEmptyFilterParams(), *cdata.NewEmptyPagingParams())
+	assert.Nil(t, err)
+	assert.Contains(t, counters.Timings(), "mysql.dummies.get_page")
+	assert.Empty(t, counters.Increments())
+
+	_, err = persistence.IdentifiableMySqlPersistence.GetPageByFilter(context.Background(), "",
+		"unknown_column=1", *cdata.NewEmptyPagingParams(), "", "")
+	assert.NotNil(t, err)
+	assert.Contains(t, counters.Increments(), "mysql.dummies.get_page.errors")
+}
+
+func TestDummyMySqlPersistenceQueriesLogging(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.log_queries", true,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Secret content"})
+	assert.Nil(t, err)
+
+	_, err = persistence.GetPageByFilter(context.Background(), "", *cdata.NewEmptyFilterParams(), *cdata.NewEmptyPagingParams())
+	assert.Nil(t, err)
+
+	logged := strings.Join(logger.Messages(), "\n")
+	assert.Contains(t, logged, "INSERT INTO `dummies` (`id`,`key`,`content`)")
+	assert.Contains(t, logged, "with 3 parameters [string,string,string]")
+	assert.Contains(t, logged, "SELECT * FROM `dummies`")
+	assert.NotContains(t, logged, "Secret content")
+
+	t.Run("DummyMySqlPersistence:EmptyIds", func(t *testing.T) {
+		before := len(logger.Messages())
+
+		items, err := persistence.GetListByIds(context.Background(), "", []string{})
+		assert.Nil(t, err)
+		assert.NotNil(t, items)
+		assert.Len(t, items, 0)
+
+		err = persistence.DeleteByIds(context.Background(), "", nil)
+		assert.Nil(t, err)
+
+		// Empty IN() lists are not sent to the server
+		assert.NotContains(t, strings.Join(logger.Messages()[before:], "\n"), "Executing query")
+	})
+}
+
+func TestDummyMySqlPersistenceReturnOnWrite(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.log_queries", true,
+		"options.return_on_write", false,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	logged := len(logger.Messages())
+
+	dummy, err := persistence.Set(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, dummy.Id)
+	assert.Equal(t, "Key 1", dummy.Key)
+
+	dummy.Content = "Content 2"
+	result, err := persistence.Update(context.Background(), "", dummy)
+	assert.Nil(t, err)
+	assert.Equal(t, dummy, result)
+
+	result, err = persistence.UpdatePartially(context.Background(), "", dummy.Id,
+		*cdata.NewAnyValueMapFromTuples("content", "Content 3"))
+	assert.Nil(t, err)
+	assert.Equal(t, dummy.Id, result.Id)
+	assert.Equal(t, "Content 3", result.Content)
+
+	// Written items are not read back
+	queries := strings.Join(logger.Messages()[logged:], "\n")
+	assert.Contains(t, queries, "Executing query UPDATE")
+	assert.NotContains(t, queries, "Executing query SELECT")
+
+	stored, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Key 1", stored.Key)
+	assert.Equal(t, "Content 3", stored.Content)
+}
+
+func TestDummyMySqlPersistenceSlowQueries(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.slow_query_threshold_ms", 100,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	// Fast queries are not logged
+	logged := len(logger.Messages())
+	_, err := persistence.GetOneById(context.Background(), "", "1")
+	assert.Nil(t, err)
+	for _, message := range logger.Messages()[logged:] {
+		assert.NotContains(t, message, "Slow MySql operation")
+	}
+
+	_, err = persistence.ExecuteQuery(context.Background(), "", "SELECT SLEEP(@seconds)",
+		map[string]any{"seconds": 0.2})
+	assert.Nil(t, err)
+
+	slow := ""
+	for _, message := range logger.Messages() {
+		if strings.Contains(message, "Slow MySql operation") {
+			slow = message
+		}
+	}
+	assert.Contains(t, slow, "execute_query")
+	assert.Contains(t, slow, "dummies")
+	// Parameter values are never logged
+	assert.NotContains(t, slow, "0.2")
+}
+
+func TestDummyMySqlPersistenceRecreateSchema(t *testing.T) {
+
+	// The test drops its table, so it uses a dedicated one
+	dbConfig := newTestDbConfig(t,
+		"table", "dummies_recreate",
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+
+	recreatingPersistence := NewDummyMySqlPersistence()
+	recreatingPersistence.Configure(context.Background(), dbConfig.Override(
+		cconf.NewConfigParamsFromTuples("options.recreate_schema", true),
+	))
+
+	for _, p := range []*DummyMySqlPersistence{persistence, recreatingPersistence} {
+		opnErr := p.Open(context.Background(), "")
+		if opnErr != nil {
+			t.Error("Error opened persistence", opnErr)
+			return
+		}
+		defer p.Close(context.Background(), "")
+	}
+	defer persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS "+persistence.QuotedTableName())
+
+	// Simulate a restored server without the schema
+	_, err := persistence.Client.ExecContext(context.Background(), "DROP TABLE "+persistence.QuotedTableName())
+	assert.Nil(t, err)
+
+	_, err = persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.NotNil(t, err)
+
+	_, err = recreatingPersistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+
+	indexes, err := recreatingPersistence.GetIndexes(context.Background(), "")
+	assert.Nil(t, err)
+	assert.Contains(t, indexes, "dummies_recreate_key")
+
+	count, err := persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDummyMySqlPersistenceCircuitBreaker(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.circuit_breaker_threshold", 2,
+		"options.circuit_breaker_cooldown_ms", 500,
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	// Force connection failures with a pool to the closed port,
+	// queries run in the current pool of the connection
+	client := persistence.Connection.GetConnection()
+	brokenClient, err := sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/test?timeout=100ms")
+	assert.Nil(t, err)
+	defer brokenClient.Close()
+	persistence.Connection.Connection = brokenClient
+
+	for i := 0; i < 2; i++ {
+		_, err = persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", "")
+		assert.NotNil(t, err)
+	}
+	assert.True(t, persistence.Connection.GetCircuitBreaker().IsOpen())
+
+	// The breaker fails fast without calling the database
+	start := time.Now()
+	_, err = persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "123", "")
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, persist.CircuitOpenErrorCode, appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+	}
+
+	// A successful probe after the cooldown closes the breaker
+	persistence.Connection.Connection = client
+	_, err = persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", "")
+	assert.NotNil(t, err)
+
+	time.Sleep(600 * time.Millisecond)
+	_, err = persistence.IdentifiableMySqlPersistence.GetCountByFilter(context.Background(), "", "")
+	assert.Nil(t, err)
+	assert.False(t, persistence.Connection.GetCircuitBreaker().IsOpen())
+}
+
+func TestDummyMySqlPersistenceSchemaDuplicates(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"table", "dummies_schema",
+		"options.log_queries", true,
+	)
+
+	logger := &captureLogger{}
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Client.ExecContext(context.Background(), "DROP TABLE "+persistence.QuotedTableName())
+	assert.Nil(t, err)
+
+	logged := len(logger.Messages())
+
+	// Defining the schema again must not duplicate statements
+	persistence.DefineSchema()
+	persistence.DefineSchema()
+
+	err = persistence.CreateSchema(context.Background(), "")
+	assert.Nil(t, err)
+
+	queries := strings.Join(logger.Messages()[logged:], "\n")
+	assert.Equal(t, 1, strings.Count(queries, "Executing query CREATE TABLE `dummies_schema`"))
+	assert.Equal(t, 1, strings.Count(queries, "Executing query CREATE UNIQUE INDEX `dummies_schema_key`"))
+}
+
+func TestDummyMySqlPersistenceSchemaQualification(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"table", "dummies_qualified",
+		"options.log_queries", true,
+	)
+
+	t.Run("DummyMySqlPersistence:SchemaIndex", func(t *testing.T) {
+		logger := &captureLogger{}
+		persistence := NewDummyMySqlPersistence()
+		persistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"schema", testDatabase(),
+		)))
+		persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+			cref.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+		))
+
+		err := persistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer persistence.Close(context.Background(), "")
+
+		// Only the table is qualified, index names can't have a schema in MySQL
+		queries := strings.Join(logger.Messages(), "\n")
+		assert.Contains(t, queries,
+			"CREATE UNIQUE INDEX `dummies_qualified_key` ON `"+testDatabase()+"`.`dummies_qualified`")
+
+		indexes, err := persistence.GetIndexes(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Contains(t, indexes, "dummies_qualified_key")
+	})
+
+	t.Run("DummyMySqlPersistence:QualifySchema", func(t *testing.T) {
+		persistence := NewDummyMySqlPersistence()
+		persistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"options.qualify_schema", true,
+		)))
+
+		err := persistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer persistence.Close(context.Background(), "")
+
+		assert.Equal(t, testDatabase(), persistence.SchemaName)
+		assert.Equal(t, "`"+testDatabase()+"`.`dummies_qualified`", persistence.QuotedTableName())
+	})
+}
+
+func TestDummyMySqlPersistenceReadReplica(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	// Another database on the same server plays the replica
+	replicaSchema := testDatabase() + "_replica"
+	_, err := persistence.Client.ExecContext(context.Background(), "CREATE SCHEMA IF NOT EXISTS `"+replicaSchema+"`")
+	assert.Nil(t, err)
+	defer persistence.Client.ExecContext(context.Background(), "DROP SCHEMA IF EXISTS `"+replicaSchema+"`")
+
+	_, err = persistence.Client.ExecContext(context.Background(),
+		"CREATE TABLE IF NOT EXISTS "+persistence.QuotedTableNameFor(replicaSchema, persistence.TableName)+
+			" LIKE "+persistence.QuotedTableName())
+	assert.Nil(t, err)
+	replica := persistence.WithSchema(replicaSchema)
+	err = replica.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer replica.Close(context.Background(), "")
+	_, err = replica.Create(context.Background(), "",
+		tf.Dummy{Id: "replica_1", Key: "Replica key", Content: "Replica content"})
+	assert.Nil(t, err)
+
+	replicaPersistence := NewDummyMySqlPersistence()
+	replicaPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		"read_connection.host", dbConfig.GetAsString("connection.host"),
+		"read_connection.port", dbConfig.GetAsString("connection.port"),
+		"read_connection.database", replicaSchema,
+	)))
+	err = replicaPersistence.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer replicaPersistence.Close(context.Background(), "")
+
+	assert.NotNil(t, replicaPersistence.ReadClient)
+
+	// Writes go to the primary
+	dummy, err := replicaPersistence.Create(context.Background(), "",
+		tf.Dummy{Id: "primary_1", Key: "Primary key", Content: "Primary content"})
+	assert.Nil(t, err)
+
+	result, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, dummy, result)
+
+	// Reads hit the replica
+	result, err = replicaPersistence.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, tf.Dummy{}, result)
+
+	result, err = replicaPersistence.GetOneById(context.Background(), "", "replica_1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Replica key", result.Key)
+
+	page, err := replicaPersistence.GetPageByFilter(context.Background(), "",
+		*cdata.NewEmptyFilterParams(), *cdata.NewEmptyPagingParams())
+	assert.Nil(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "replica_1", page.Data[0].Id)
+
+	count, err := replicaPersistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Without the replica reads go to the primary
+	assert.Nil(t, persistence.ReadClient)
+	count, err = persistence.GetCountByFilter(context.Background(), "", *cdata.NewEmptyFilterParams())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDummyMySqlPersistenceDisableForeignKeys(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"table", "dummies_parents",
+		"options.max_pool_size", 2,
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS `dummies_children`")
+	assert.Nil(t, err)
+	if err := persistence.Clear(context.Background(), ""); err != nil {
+		t.Error("Error cleaned persistence", err)
+		return
+	}
+
+	_, err = persistence.Client.ExecContext(context.Background(),
+		"CREATE TABLE `dummies_children` (id VARCHAR(32) PRIMARY KEY, parent_id VARCHAR(32),"+
+			" FOREIGN KEY (parent_id) REFERENCES `dummies_parents` (id))")
+	assert.Nil(t, err)
+	defer persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS `dummies_children`")
+
+	parent, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Parent key", Content: "Parent content"})
+	assert.Nil(t, err)
+	_, err = persistence.Client.ExecContext(context.Background(),
+		"INSERT INTO `dummies_children` (id, parent_id) VALUES ('child_1', ?)", parent.Id)
+	assert.Nil(t, err)
+
+	// Parent with children can't be deleted by default
+	_, err = persistence.DeleteById(context.Background(), "", parent.Id)
+	assert.NotNil(t, err)
+
+	fkPersistence := NewDummyMySqlPersistence()
+	fkPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		"options.disable_foreign_keys_on_delete", true,
+	)))
+	err = fkPersistence.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer fkPersistence.Close(context.Background(), "")
+
+	result, err := fkPersistence.DeleteById(context.Background(), "", parent.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, parent, result)
+
+	result, err = fkPersistence.GetOneById(context.Background(), "", parent.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, tf.Dummy{}, result)
+
+	// Children are left for cleanup by the caller
+	var count int
+	err = fkPersistence.Client.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM `dummies_children`").Scan(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	// Checks are enabled again in all pooled connections
+	for i := 0; i < 2; i++ {
+		dbConn, err := fkPersistence.Client.Conn(context.Background())
+		assert.Nil(t, err)
+		defer dbConn.Close()
+
+		var checks int
+		err = dbConn.QueryRowContext(context.Background(), "SELECT @@FOREIGN_KEY_CHECKS").Scan(&checks)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, checks)
+	}
+
+	// The deleted item is read before the delete takes the only pooled connection
+	singlePersistence := NewDummyMySqlPersistence()
+	singlePersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		"options.disable_foreign_keys_on_delete", true,
+		"options.max_pool_size", 1,
+	)))
+	err = singlePersistence.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer singlePersistence.Close(context.Background(), "")
+
+	parent, err = singlePersistence.Create(context.Background(), "", tf.Dummy{Key: "Single key", Content: "Single content"})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err = singlePersistence.DeleteById(ctx, "", parent.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, parent, result)
+}
+
+func TestDummyMySqlPersistenceReconnect(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	dummy, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+
+	oldClient := persistence.Client
+	err = persistence.Reconnect(context.Background(), "")
+	assert.Nil(t, err)
+	assert.NotSame(t, oldClient, persistence.Client)
+
+	// Queries run in the new pool
+	result, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, dummy, result)
+
+	_, err = persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 2", Content: "Content 2"})
+	assert.Nil(t, err)
+}
+
+func TestDummyMySqlPersistenceReconnectConcurrent(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	if err := connection.Open(context.Background(), ""); err != nil {
+		t.Fatal("Error opened connection", err)
+	}
+	t.Cleanup(func() { connection.Close(context.Background(), "") })
+
+	// Both persistence components share the connection
+	descr := cref.NewDescriptor("pip-services", "connection", "mysql", "default", "1.0")
+	references := cref.NewReferencesFromTuples(context.Background(), descr, connection)
+	persistence := NewDummyMySqlPersistence()
+	persistence.SetReferences(context.Background(), references)
+	openTestPersistence(t, persistence)
+	shared := NewDummyMySqlPersistence()
+	shared.SetReferences(context.Background(), references)
+	openTestPersistence(t, shared)
+
+	dummy, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				_, err := shared.GetOneById(context.Background(), "", dummy.Id)
+				assert.Nil(t, err)
+				_, err = shared.Create(context.Background(), "", tf.Dummy{
+					Key:     "Key " + strconv.Itoa(i) + "-" + strconv.Itoa(j),
+					Content: "Content",
+				})
+				assert.Nil(t, err)
+			}
+		}(i)
+	}
+
+	for i := 0; i < 5; i++ {
+		err = persistence.Reconnect(context.Background(), "")
+		assert.Nil(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(done)
+	wg.Wait()
+
+	// The persistence sharing the connection runs in the new pool without reconnecting
+	result, err := shared.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, dummy, result)
+}
+
+func TestDummyMySqlPersistenceViewShares(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	cache := newCaptureCache()
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	persistence.SetReferences(context.Background(), cref.NewReferencesFromTuples(context.Background(),
+		cref.NewDescriptor("pip-services", "cache", "capture", "default", "1.0"), cache,
+	))
+	openTestPersistence(t, persistence)
+
+	_, err := persistence.Client.ExecContext(context.Background(),
+		"CREATE TABLE IF NOT EXISTS `dummies_view` LIKE "+persistence.QuotedTableName())
+	assert.Nil(t, err)
+	defer persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS `dummies_view`")
+
+	view := persistence.WithTable("dummies_view")
+	assert.Same(t, persistence.Counters, view.Counters)
+	err = view.Open(context.Background(), "")
+	assert.Nil(t, err)
+
+	// Items read by the view are kept in the cache of the persistence
+	dummy, err := view.Create(context.Background(), "", tf.Dummy{Key: "View key", Content: "View content"})
+	assert.Nil(t, err)
+	_, err = view.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	result, err := view.GetOneById(context.Background(), "", dummy.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, dummy, result)
+	assert.Equal(t, 1, cache.Hits())
+
+	// Closing the view keeps the persistence working
+	err = view.Close(context.Background(), "")
+	assert.Nil(t, err)
+	_, err = persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+}
+
+func TestDummyMySqlPersistenceMissingTable(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.auto_create_schema", false,
+	)
+
+	// Objects are not created, so the missing table fails the opening
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		"table", "dummies_missing",
+	)))
+	err := persistence.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.False(t, persistence.IsOpen())
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, "TABLE_NOT_FOUND", appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+		assert.Contains(t, appErr.Message, "dummies_missing")
+	}
+
+	// The existing table is opened as usual
+	created := NewDummyMySqlPersistence()
+	created.Configure(context.Background(), newTestDbConfig(t))
+	openTestPersistence(t, created)
+
+	existing := NewDummyMySqlPersistence()
+	existing.Configure(context.Background(), dbConfig)
+	err = existing.Open(context.Background(), "")
+	assert.Nil(t, err)
+	defer existing.Close(context.Background(), "")
 }
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"context"
+
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyNestedMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyNested, string]
+}
+
+func NewDummyNestedMySqlPersistence() *DummyNestedMySqlPersistence {
+	c := &DummyNestedMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyNested, string](c, "dummies_nested")
+	return c
+}
+
+func (c *DummyNestedMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50))")
+	c.EnsureSchema("CREATE TABLE IF NOT EXISTS `dummies_nested_address` (dummy_id VARCHAR(32) PRIMARY KEY, `city` VARCHAR(50), `zip` VARCHAR(10))")
+}
+
+// ConvertFromPublic writes only columns of the table, the address is stored in the joined table
+func (c *DummyNestedMySqlPersistence) ConvertFromPublic(item fixtures.DummyNested) (map[string]any, error) {
+	return map[string]any{"id": item.Id, "key": item.Key}, nil
+}
+
+// GetListWithAddress reads dummies joined with their addresses into the nested Address field
+func (c *DummyNestedMySqlPersistence) GetListWithAddress(ctx context.Context, correlationId string) ([]fixtures.DummyNested, error) {
+	query := "SELECT d.id, d.`key`, a.city AS address__city, a.zip AS address__zip FROM " + c.QuotedTableName() +
+		" d LEFT JOIN `dummies_nested_address` a ON a.dummy_id=d.id ORDER BY d.id"
+	rows, err := c.Client.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]fixtures.DummyNested, 0)
+	for rows.Next() {
+		item, err := c.ConvertToPublic(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyNestedMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.nested_separator", "__",
+	)
+
+	persistence := NewDummyNestedMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+	if _, err := persistence.Client.ExecContext(context.Background(), "DELETE FROM `dummies_nested_address`"); err != nil {
+		t.Error("Error cleaned persistence", err)
+		return
+	}
+
+	t.Run("DummyNestedMySqlPersistence:NestedColumns", func(t *testing.T) {
+		_, err := persistence.Create(context.Background(), "", tf.DummyNested{Id: "1", Key: "Key 1"})
+		assert.Nil(t, err)
+		_, err = persistence.Create(context.Background(), "", tf.DummyNested{Id: "2", Key: "Key 2"})
+		assert.Nil(t, err)
+		_, err = persistence.Client.ExecContext(context.Background(),
+			"INSERT INTO `dummies_nested_address` (dummy_id, city, zip) VALUES (?, ?, ?)", "1", "Denver", "80202")
+		assert.Nil(t, err)
+
+		items, err := persistence.GetListWithAddress(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+		if len(items) == 2 {
+			assert.Equal(t, "Key 1", items[0].Key)
+			assert.Equal(t, tf.DummyAddress{City: "Denver", Zip: "80202"}, items[0].Address)
+			// Missing joined values are read as empty fields
+			assert.Equal(t, "Key 2", items[1].Key)
+			assert.Equal(t, tf.DummyAddress{}, items[1].Address)
+		}
+	})
+}
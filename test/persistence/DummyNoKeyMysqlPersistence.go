@@ -0,0 +1,22 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyNoKeyMySqlPersistence struct {
+	*DummyMySqlPersistence
+}
+
+func NewDummyNoKeyMySqlPersistence() *DummyNoKeyMySqlPersistence {
+	c := &DummyNoKeyMySqlPersistence{}
+	c.DummyMySqlPersistence = &DummyMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.Dummy, string](c, "dummies_nokey")
+	return c
+}
+
+func (c *DummyNoKeyMySqlPersistence) DefineSchema() {
+	// The table intentionally has no primary or unique keys
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32), `key` VARCHAR(50), `content` TEXT)")
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyNoKeyMySqlPersistence(t *testing.T) {
+
+	var persistence *DummyNoKeyMySqlPersistence
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence = NewDummyNoKeyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyNoKeyMySqlPersistence:Set", func(t *testing.T) {
+		dummy := tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"}
+
+		result, err := persistence.Set(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy, result)
+
+		// Second set must update the row instead of inserting a duplicate
+		dummy.Content = "Updated content 1"
+		result, err = persistence.Set(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy, result)
+
+		items, err := persistence.GetListByIds(context.Background(), "", []string{dummy.Id})
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, "Updated content 1", items[0].Content)
+	})
+}
+
+func TestDummyNoKeyMySqlPersistenceCircuitBreaker(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.circuit_breaker_threshold", 1,
+		"options.circuit_breaker_cooldown_ms", 60000,
+	)
+
+	persistence := NewDummyNoKeyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	// The transaction of Set fails fast like other writes when the breaker is open
+	persistence.Connection.GetCircuitBreaker().Failure()
+	_, err := persistence.Set(context.Background(), "123", tf.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"})
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, persist.CircuitOpenErrorCode, appErr.Code)
+		assert.Equal(t, "123", appErr.CorrelationId)
+	}
+}
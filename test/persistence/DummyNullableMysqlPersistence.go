@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyNullableMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyNullable, string]
+}
+
+func NewDummyNullableMySqlPersistence() *DummyNullableMySqlPersistence {
+	c := &DummyNullableMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyNullable, string](c, "dummies_nullable")
+	return c
+}
+
+func (c *DummyNullableMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT NULL)")
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyNullableMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	nullPersistence := NewDummyNullableMySqlPersistence()
+	nullPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+		"options.null_as_empty", false,
+	)))
+
+	emptyPersistence := NewDummyNullableMySqlPersistence()
+	emptyPersistence.Configure(context.Background(), dbConfig)
+
+	opnErr := nullPersistence.Open(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error opened persistence", opnErr)
+		return
+	}
+	defer func() {
+		err := nullPersistence.Close(context.Background(), "")
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	opnErr = emptyPersistence.Open(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error opened persistence", opnErr)
+		return
+	}
+	defer func() {
+		err := emptyPersistence.Close(context.Background(), "")
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	opnErr = nullPersistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	dummy, err := nullPersistence.Create(context.Background(), "", tf.DummyNullable{Id: "1", Key: "Key 1"})
+	assert.Nil(t, err)
+
+	t.Run("DummyNullableMySqlPersistence:NullAsNull", func(t *testing.T) {
+		result, err := nullPersistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy.Key, result.Key)
+		assert.Nil(t, result.Content)
+	})
+
+	t.Run("DummyNullableMySqlPersistence:NullAsEmpty", func(t *testing.T) {
+		result, err := emptyPersistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, dummy.Key, result.Key)
+		assert.NotNil(t, result.Content)
+		assert.Equal(t, "", *result.Content)
+	})
+}
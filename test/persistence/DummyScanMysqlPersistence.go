@@ -0,0 +1,28 @@
+package test
+
+import (
+	"database/sql"
+
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+// DummyScanMySqlPersistence reads rows with the row scanner instead of the JSON conversion
+type DummyScanMySqlPersistence struct {
+	*DummyMySqlPersistence
+}
+
+func NewDummyScanMySqlPersistence() *DummyScanMySqlPersistence {
+	c := &DummyScanMySqlPersistence{DummyMySqlPersistence: &DummyMySqlPersistence{}}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.Dummy, string](c, "dummies_scan")
+	return c
+}
+
+func (c *DummyScanMySqlPersistence) ScanRow(rows *sql.Rows) (fixtures.Dummy, error) {
+	var item fixtures.Dummy
+	var key, content sql.NullString
+	err := rows.Scan(&item.Id, &key, &content)
+	item.Key = key.String
+	item.Content = content.String
+	return item, err
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+func newDummyScanMySqlPersistence(tb testing.TB) *DummyScanMySqlPersistence {
+
+	mysqlUri := os.Getenv("MYSQL_URI")
+	mysqlHost := os.Getenv("MYSQL_HOST")
+	if mysqlHost == "" {
+		mysqlHost = "localhost"
+	}
+
+	mysqlPort := os.Getenv("MYSQL_PORT")
+	if mysqlPort == "" {
+		mysqlPort = "3306"
+	}
+
+	mysqlDatabase := os.Getenv("MYSQL_DB")
+	if mysqlDatabase == "" {
+		mysqlDatabase = "test"
+	}
+
+	mysqlUser := os.Getenv("MYSQL_USER")
+	if mysqlUser == "" {
+		mysqlUser = "user"
+	}
+	mysqlPassword := os.Getenv("MYSQL_PASSWORD")
+	if mysqlPassword == "" {
+		mysqlPassword = "password"
+	}
+
+	if mysqlUri == "" && mysqlHost == "" {
+		tb.Skip("Connection params not set")
+	}
+
+	dbConfig := cconf.NewConfigParamsFromTuples(
+		"connection.uri", mysqlUri,
+		"connection.host", mysqlHost,
+		"connection.port", mysqlPort,
+		"connection.database", mysqlDatabase,
+		"credential.username", mysqlUser,
+		"credential.password", mysqlPassword,
+	)
+
+	persistence := NewDummyScanMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+
+	opnErr := persistence.Open(context.Background(), "")
+	if opnErr != nil {
+		tb.Fatal("Error opened persistence", opnErr)
+	}
+	tb.Cleanup(func() {
+		err := persistence.Close(context.Background(), "")
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	opnErr = persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		tb.Fatal("Error cleaned persistence", opnErr)
+	}
+	return persistence
+}
+
+func TestDummyScanMySqlPersistence(t *testing.T) {
+	persistence := newDummyScanMySqlPersistence(t)
+	fixture := *tf.NewDummyPersistenceFixture(persistence)
+
+	t.Run("DummyScanMySqlPersistence:CRUD", fixture.TestCrudOperations)
+
+	opnErr := persistence.Clear(context.Background(), "")
+	if opnErr != nil {
+		t.Error("Error cleaned persistence", opnErr)
+		return
+	}
+
+	t.Run("DummyScanMySqlPersistence:Batch", fixture.TestBatchOperations)
+}
+
+func BenchmarkDummyScanMySqlPersistence(b *testing.B) {
+	persistence := newDummyScanMySqlPersistence(b)
+
+	// Insert 100k rows in batches
+	const rowsCount = 100000
+	const batchSize = 1000
+	for i := 0; i < rowsCount; i += batchSize {
+		values := make([]string, 0, batchSize)
+		args := make([]any, 0, batchSize*3)
+		for j := i; j < i+batchSize; j++ {
+			values = append(values, "(?,?,?)")
+			args = append(args, strconv.Itoa(j), "Key "+strconv.Itoa(j), "Content "+strconv.Itoa(j))
+		}
+		_, err := persistence.Client.ExecContext(context.Background(),
+			"INSERT INTO "+persistence.QuotedTableName()+" (`id`,`key`,`content`) VALUES "+strings.Join(values, ","), args...)
+		if err != nil {
+			b.Fatal("Error inserted rows", err)
+		}
+	}
+
+	readAll := func(b *testing.B, convert func(rows *sql.Rows) (tf.Dummy, error)) {
+		for n := 0; n < b.N; n++ {
+			rows, err := persistence.Client.QueryContext(context.Background(), "SELECT * FROM "+persistence.QuotedTableName())
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			count := 0
+			for rows.Next() {
+				if _, err = convert(rows); err != nil {
+					b.Fatal(err)
+				}
+				count++
+			}
+			rows.Close()
+			if count != rowsCount {
+				b.Fatalf("Expected %d rows, got %d", rowsCount, count)
+			}
+		}
+	}
+
+	b.ResetTimer()
+
+	b.Run("Json", func(b *testing.B) {
+		readAll(b, persistence.ConvertToPublic)
+	})
+
+	b.Run("Scan", func(b *testing.B) {
+		readAll(b, persistence.ScanRow)
+	})
+}
@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummySetMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummySet, string]
+}
+
+func NewDummySetMySqlPersistence() *DummySetMySqlPersistence {
+	c := &DummySetMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummySet, string](c, "dummies_set")
+	return c
+}
+
+func (c *DummySetMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `tags` SET('red','green','blue'))")
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummySetMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.split_set_columns", true,
+	)
+
+	persistence := NewDummySetMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummySetMySqlPersistence:SetColumn", func(t *testing.T) {
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.DummySet{Id: "1", Key: "Key 1", Tags: []string{"red", "blue"}})
+		assert.Nil(t, err)
+
+		result, err := persistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"red", "blue"}, result.Tags)
+
+		// The empty set is read as an empty array
+		_, err = persistence.Set(context.Background(), "",
+			tf.DummySet{Id: "1", Key: "Key 1", Tags: []string{}})
+		assert.Nil(t, err)
+
+		result, err = persistence.GetOneById(context.Background(), "", dummy.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{}, result.Tags)
+	})
+}
+
+func TestDummySetMySqlPersistenceSqlMock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+
+	persistence := NewDummySetMySqlPersistence()
+	persistence.Configure(context.Background(), cconf.NewConfigParamsFromTuples(
+		"options.split_set_columns", true,
+	))
+	persistence.SetClient(db, "test")
+
+	columns := []*sqlmock.Column{
+		mock.NewColumn("id").OfType("VARCHAR", ""),
+		mock.NewColumn("key").OfType("VARCHAR", ""),
+		mock.NewColumn("tags").OfType("SET", ""),
+	}
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRowsWithColumnDefinition(columns...).
+			AddRow("1", "Key 1", "red,blue").
+			AddRow("2", "Key 2", ""))
+
+	items, err := persistence.GetListByIds(context.Background(), "", []string{"1", "2"})
+	assert.Nil(t, err)
+	assert.Len(t, items, 2)
+	if len(items) == 2 {
+		assert.Equal(t, []string{"red", "blue"}, items[0].Tags)
+		assert.Equal(t, []string{}, items[1].Tags)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
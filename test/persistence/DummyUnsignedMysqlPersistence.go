@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyUnsignedMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyUnsigned, uint64]
+}
+
+func NewDummyUnsignedMySqlPersistence() *DummyUnsignedMySqlPersistence {
+	c := &DummyUnsignedMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyUnsigned, uint64](c, "dummies_unsigned")
+	return c
+}
+
+func (c *DummyUnsignedMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id BIGINT UNSIGNED PRIMARY KEY, `key` VARCHAR(50), `total` BIGINT UNSIGNED NULL)")
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyUnsignedMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	persistence := NewDummyUnsignedMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyUnsignedMySqlPersistence:MaxId", func(t *testing.T) {
+		// Ids above int64 and float64 precision must be kept exactly
+		ids := []uint64{math.MaxUint64 - 1, math.MaxUint64 - 2}
+
+		for i, id := range ids {
+			dummy, err := persistence.Create(context.Background(), "",
+				tf.DummyUnsigned{Id: id, Key: "Key " + strconv.Itoa(i)})
+			assert.Nil(t, err)
+			assert.Equal(t, id, dummy.Id)
+		}
+
+		result, err := persistence.GetOneById(context.Background(), "", ids[0])
+		assert.Nil(t, err)
+		assert.Equal(t, ids[0], result.Id)
+		assert.Equal(t, "Key 0", result.Key)
+
+		result, err = persistence.Update(context.Background(), "",
+			tf.DummyUnsigned{Id: ids[1], Key: "Updated key"})
+		assert.Nil(t, err)
+		assert.Equal(t, ids[1], result.Id)
+		assert.Equal(t, "Updated key", result.Key)
+
+		items, err := persistence.GetListByIds(context.Background(), "", ids)
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+
+		err = persistence.DeleteByIds(context.Background(), "", ids)
+		assert.Nil(t, err)
+
+		items, err = persistence.GetListByIds(context.Background(), "", ids)
+		assert.Nil(t, err)
+		assert.Len(t, items, 0)
+	})
+
+	t.Run("DummyUnsignedMySqlPersistence:MapValues", func(t *testing.T) {
+		// Numbers of maps are converted by the column types
+		mapPersistence := NewDummyMapMySqlPersistence()
+		mapPersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"table", "dummies_unsigned",
+		)))
+		err := mapPersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer mapPersistence.Close(context.Background(), "")
+
+		id := uint64(math.MaxUint64 - 3)
+		total := uint64(1<<53 + 1)
+		_, err = mapPersistence.Create(context.Background(), "",
+			map[string]any{"id": id, "key": "Key 3", "total": total})
+		assert.Nil(t, err)
+
+		var storedId, storedTotal uint64
+		err = persistence.Client.QueryRowContext(context.Background(),
+			"SELECT `id`, `total` FROM `dummies_unsigned` WHERE `key`='Key 3'").Scan(&storedId, &storedTotal)
+		assert.Nil(t, err)
+		assert.Equal(t, id, storedId)
+		assert.Equal(t, total, storedTotal)
+
+		// String ids are bound as integers, as strings they match all ids with the same float64 value
+		_, err = mapPersistence.Create(context.Background(), "",
+			map[string]any{"id": id - 1, "key": "Key 4"})
+		assert.Nil(t, err)
+
+		items, err := mapPersistence.GetListByIds(context.Background(), "", []string{strconv.FormatUint(id, 10)})
+		assert.Nil(t, err)
+		assert.Len(t, items, 1)
+
+		err = mapPersistence.DeleteByIds(context.Background(), "", []string{strconv.FormatUint(id, 10)})
+		assert.Nil(t, err)
+
+		count, err := persistence.CountAll(context.Background(), "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		err = persistence.DeleteByIds(context.Background(), "", []uint64{id - 1})
+		assert.Nil(t, err)
+	})
+}
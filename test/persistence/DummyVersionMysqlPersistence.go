@@ -0,0 +1,21 @@
+package test
+
+import (
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+)
+
+type DummyVersionMySqlPersistence struct {
+	*persist.IdentifiableMySqlPersistence[fixtures.DummyVersion, string]
+}
+
+func NewDummyVersionMySqlPersistence() *DummyVersionMySqlPersistence {
+	c := &DummyVersionMySqlPersistence{}
+	c.IdentifiableMySqlPersistence = persist.InheritIdentifiableMySqlPersistence[fixtures.DummyVersion, string](c, "dummies_version")
+	return c
+}
+
+func (c *DummyVersionMySqlPersistence) DefineSchema() {
+	c.IdentifiableMySqlPersistence.DefineSchema()
+	c.EnsureSchema("CREATE TABLE `" + c.TableName + "` (id VARCHAR(32) PRIMARY KEY, `key` VARCHAR(50), `content` TEXT, `version` BIGINT NOT NULL DEFAULT 0)")
+}
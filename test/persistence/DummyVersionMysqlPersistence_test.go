@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cdata "github.com/pip-services3-gox/pip-services3-commons-gox/data"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDummyVersionMySqlPersistence(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"options.version_column", "version",
+	)
+
+	persistence := NewDummyVersionMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	t.Run("DummyVersionMySqlPersistence:Update", func(t *testing.T) {
+		dummy, err := persistence.Create(context.Background(), "",
+			tf.DummyVersion{Id: "1", Key: "Key 1", Content: "Content 1"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), dummy.Version)
+
+		// Two clients read the same version and update it concurrently
+		first, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		second, err := persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+
+		first.Content = "Content 2"
+		result, err := persistence.Update(context.Background(), "", first)
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 2", result.Content)
+		assert.Equal(t, int64(1), result.Version)
+
+		// The stale update must not overwrite the first one
+		second.Content = "Content 3"
+		_, err = persistence.Update(context.Background(), "", second)
+		assert.NotNil(t, err)
+		appErr, ok := err.(*cerr.ApplicationError)
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "VERSION_CONFLICT", appErr.Code)
+		}
+
+		result, err = persistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 2", result.Content)
+		assert.Equal(t, int64(1), result.Version)
+	})
+
+	t.Run("DummyVersionMySqlPersistence:UpdatePartially", func(t *testing.T) {
+		_, err := persistence.UpdatePartially(context.Background(), "", "1",
+			*cdata.NewAnyValueMapFromTuples("content", "Content 4", "version", 0))
+		assert.NotNil(t, err)
+
+		result, err := persistence.UpdatePartially(context.Background(), "", "1",
+			*cdata.NewAnyValueMapFromTuples("content", "Content 4", "version", 1))
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 4", result.Content)
+		assert.Equal(t, int64(2), result.Version)
+
+		// Without the version the item is updated unconditionally
+		result, err = persistence.UpdatePartially(context.Background(), "", "1",
+			*cdata.NewAnyValueMapFromTuples("content", "Content 5"))
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 5", result.Content)
+		assert.Equal(t, int64(3), result.Version)
+	})
+
+	t.Run("DummyVersionMySqlPersistence:NoReturnOnWrite", func(t *testing.T) {
+		writePersistence := NewDummyVersionMySqlPersistence()
+		writePersistence.Configure(context.Background(), dbConfig.Override(cconf.NewConfigParamsFromTuples(
+			"options.return_on_write", false,
+		)))
+		err := writePersistence.Open(context.Background(), "")
+		assert.Nil(t, err)
+		defer writePersistence.Close(context.Background(), "")
+
+		dummy, err := writePersistence.GetOneById(context.Background(), "", "1")
+		assert.Nil(t, err)
+
+		// Each update returns the incremented version to pass in the next one
+		dummy.Content = "Content 6"
+		dummy, err = writePersistence.Update(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), dummy.Version)
+
+		dummy.Content = "Content 7"
+		dummy, err = writePersistence.Update(context.Background(), "", dummy)
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 7", dummy.Content)
+		assert.Equal(t, int64(5), dummy.Version)
+
+		dummy, err = writePersistence.UpdatePartially(context.Background(), "", "1",
+			*cdata.NewAnyValueMapFromTuples("content", "Content 8", "version", dummy.Version))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(6), dummy.Version)
+
+		dummy, err = writePersistence.UpdatePartially(context.Background(), "", "1",
+			*cdata.NewAnyValueMapFromTuples("content", "Content 9", "version", dummy.Version))
+		assert.Nil(t, err)
+		assert.Equal(t, "Content 9", dummy.Content)
+		assert.Equal(t, int64(7), dummy.Version)
+	})
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	tf "github.com/pip-services3-gox/pip-services3-mysql-gox/test/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t,
+		"table", "dummies_migrations",
+		"options.migrations_table", "dummies_migrations_versions",
+	)
+
+	persistence := NewDummyMySqlPersistence()
+	persistence.Configure(context.Background(), dbConfig)
+	openTestPersistence(t, persistence)
+
+	// Start from the table without migrations
+	_, err := persistence.Client.ExecContext(context.Background(), "DROP TABLE IF EXISTS `dummies_migrations_versions`")
+	assert.Nil(t, err)
+	_, err = persistence.Client.ExecContext(context.Background(), "DROP TABLE "+persistence.QuotedTableName())
+	assert.Nil(t, err)
+	err = persistence.CreateSchema(context.Background(), "")
+	assert.Nil(t, err)
+
+	dummy, err := persistence.Create(context.Background(), "", tf.Dummy{Key: "Key 1", Content: "Content 1"})
+	assert.Nil(t, err)
+
+	migrations := []persist.Migration{
+		{
+			Version:     2,
+			Description: "Set priority of existing items",
+			Statements:  []string{"UPDATE `dummies_migrations` SET `priority`=`priority`+1"},
+		},
+		{
+			Version:     1,
+			Description: "Add priority",
+			Statements:  []string{"ALTER TABLE `dummies_migrations` ADD `priority` INT NOT NULL DEFAULT 0"},
+		},
+	}
+
+	err = persistence.RunMigrations(context.Background(), "", migrations)
+	assert.Nil(t, err)
+
+	// Applied migrations are skipped on the next run
+	err = persistence.RunMigrations(context.Background(), "", migrations)
+	assert.Nil(t, err)
+
+	var priority int
+	err = persistence.Client.QueryRowContext(context.Background(),
+		"SELECT `priority` FROM `dummies_migrations` WHERE id=?", dummy.Id).Scan(&priority)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, priority)
+
+	var count int
+	err = persistence.Client.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM `dummies_migrations_versions` WHERE `table_name`=?", persistence.TableName).Scan(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// A failed migration is not saved as applied
+	err = persistence.RunMigrations(context.Background(), "", append(migrations, persist.Migration{
+		Version:    3,
+		Statements: []string{"UPDATE `dummies_migrations` SET `missing`=1"},
+	}))
+	assert.NotNil(t, err)
+
+	err = persistence.Client.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM `dummies_migrations_versions` WHERE `table_name`=?", persistence.TableName).Scan(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
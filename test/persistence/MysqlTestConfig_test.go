@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	cconf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+)
+
+// testPersistence is a persistence which can be opened and cleaned by tests.
+type testPersistence interface {
+	Open(ctx context.Context, correlationId string) error
+	Close(ctx context.Context, correlationId string) error
+	Clear(ctx context.Context, correlationId string) error
+}
+
+// testDatabase gets the name of the test database.
+func testDatabase() string {
+	mysqlDatabase := os.Getenv("MYSQL_DB")
+	if mysqlDatabase == "" {
+		mysqlDatabase = "test"
+	}
+	return mysqlDatabase
+}
+
+// newTestDbConfig creates connection parameters of the test database set by environment variables
+// followed by the additional tuples. The test is skipped when the connection is not set.
+func newTestDbConfig(t testing.TB, tuples ...any) *cconf.ConfigParams {
+	mysqlUri := os.Getenv("MYSQL_URI")
+	mysqlHost := os.Getenv("MYSQL_HOST")
+	if mysqlHost == "" {
+		mysqlHost = "localhost"
+	}
+
+	mysqlPort := os.Getenv("MYSQL_PORT")
+	if mysqlPort == "" {
+		mysqlPort = "3306"
+	}
+
+	mysqlUser := os.Getenv("MYSQL_USER")
+	if mysqlUser == "" {
+		mysqlUser = "user"
+	}
+	mysqlPassword := os.Getenv("MYSQL_PASSWORD")
+	if mysqlPassword == "" {
+		mysqlPassword = "password"
+	}
+
+	if mysqlUri == "" && mysqlHost == "" {
+		t.Skip("Connection params not set")
+	}
+
+	return cconf.NewConfigParamsFromTuples(append([]any{
+		"connection.uri", mysqlUri,
+		"connection.host", mysqlHost,
+		"connection.port", mysqlPort,
+		"connection.database", testDatabase(),
+		"credential.username", mysqlUser,
+		"credential.password", mysqlPassword,
+	}, tuples...)...)
+}
+
+// openTestPersistence opens the configured persistence and cleans its data.
+// The persistence is closed when the test ends.
+func openTestPersistence(t testing.TB, persistence testPersistence) {
+	if err := persistence.Open(context.Background(), ""); err != nil {
+		t.Fatal("Error opened persistence", err)
+	}
+
+	t.Cleanup(func() {
+		if err := persistence.Close(context.Background(), ""); err != nil {
+			t.Error("Error closed persistence", err)
+		}
+	})
+
+	if err := persistence.Clear(context.Background(), ""); err != nil {
+		t.Fatal("Error cleaned persistence", err)
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	conn "github.com/pip-services3-gox/pip-services3-mysql-gox/connect"
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementCache(t *testing.T) {
+
+	dbConfig := newTestDbConfig(t)
+
+	connection := conn.NewMySqlConnection()
+	connection.Configure(context.Background(), dbConfig)
+	err := connection.Open(context.Background(), "")
+	if err != nil {
+		t.Error("Error opened connection", err)
+		return
+	}
+	defer connection.Close(context.Background(), "")
+
+	cache := persist.NewStatementCache(connection.GetConnection(), 2)
+	defer cache.Close()
+
+	stmt1, release1, err := cache.Prepare(context.Background(), "SELECT 1")
+	assert.Nil(t, err)
+	release1()
+	stmt2, release2, err := cache.Prepare(context.Background(), "SELECT 2")
+	assert.Nil(t, err)
+	release2()
+
+	// Cached statement is reused
+	stmt, release, err := cache.Prepare(context.Background(), "SELECT 1")
+	assert.Nil(t, err)
+	assert.Same(t, stmt1, stmt)
+	release()
+
+	// The least recently used statement is evicted and closed
+	stmt3, release3, err := cache.Prepare(context.Background(), "SELECT 3")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cache.Len())
+
+	var value int
+	err = stmt2.QueryRowContext(context.Background()).Scan(&value)
+	assert.NotNil(t, err)
+
+	err = stmt1.QueryRowContext(context.Background()).Scan(&value)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, value)
+
+	for _, query := range []string{"SELECT 4", "SELECT 5", "SELECT 6"} {
+		_, release, err = cache.Prepare(context.Background(), query)
+		assert.Nil(t, err)
+		release()
+		assert.Equal(t, 2, cache.Len())
+	}
+
+	err = stmt1.QueryRowContext(context.Background()).Scan(&value)
+	assert.NotNil(t, err)
+
+	// The leased statement stays open after eviction until it is released
+	err = stmt3.QueryRowContext(context.Background()).Scan(&value)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, value)
+
+	release3()
+	err = stmt3.QueryRowContext(context.Background()).Scan(&value)
+	assert.NotNil(t, err)
+
+	err = cache.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"testing"
+
+	persist "github.com/pip-services3-gox/pip-services3-mysql-gox/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneMapValue(t *testing.T) {
+	item := map[string]any{
+		"id":    "1",
+		"count": 5,
+		"tags":  []any{"a", "b"},
+		"data":  map[string]any{"key": "value"},
+	}
+
+	clone := persist.CloneMapValue(item).(map[string]any)
+	assert.Equal(t, item, clone)
+	// Value types must be kept
+	assert.IsType(t, 5, clone["count"])
+
+	// Changes in the clone must not affect the original
+	clone["id"] = "2"
+	clone["tags"].([]any)[0] = "c"
+	clone["data"].(map[string]any)["key"] = "other"
+
+	assert.Equal(t, "1", item["id"])
+	assert.Equal(t, "a", item["tags"].([]any)[0])
+	assert.Equal(t, "value", item["data"].(map[string]any)["key"])
+}
+
+func TestReplaceNamedParameters(t *testing.T) {
+	query, args, err := persist.ReplaceNamedParameters("",
+		"UPDATE `dummies` SET `content`=@content WHERE `key`=@key OR `content`=@content",
+		map[string]any{"key": "Key 1", "content": "Content 1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `dummies` SET `content`=? WHERE `key`=? OR `content`=?", query)
+	// Values follow the order of placeholders, repeated parameters are bound again
+	assert.Equal(t, []any{"Content 1", "Key 1", "Content 1"}, args)
+
+	// Quoted text and system variables are not parameters
+	query, args, err = persist.ReplaceNamedParameters("",
+		"SELECT '@key', \"it\\'s @key\", `@key`, @@session.sql_mode FROM `dummies` WHERE id=@id_1",
+		map[string]any{"id_1": "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT '@key', \"it\\'s @key\", `@key`, @@session.sql_mode FROM `dummies` WHERE id=?", query)
+	assert.Equal(t, []any{"1"}, args)
+
+	// Comments are not parsed for parameters
+	query, args, err = persist.ReplaceNamedParameters("",
+		"SELECT * FROM `dummies` -- by @key\n# or @content\nWHERE /* @id */ id=@id AND 1--1",
+		map[string]any{"id": "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM `dummies` -- by @key\n# or @content\nWHERE /* @id */ id=? AND 1--1", query)
+	assert.Equal(t, []any{"1"}, args)
+
+	_, _, err = persist.ReplaceNamedParameters("", "SELECT * FROM `dummies` WHERE id=@id", map[string]any{})
+	assert.NotNil(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := persist.SplitStatements("CREATE TABLE `dummies` (id VARCHAR(32) PRIMARY KEY);\n" +
+		"CREATE INDEX `dummies_id` ON `dummies` (id);\n")
+	assert.Equal(t, []string{
+		"CREATE TABLE `dummies` (id VARCHAR(32) PRIMARY KEY)",
+		"CREATE INDEX `dummies_id` ON `dummies` (id)",
+	}, statements)
+
+	// Semicolons in quoted text and comments don't separate statements
+	statements = persist.SplitStatements("INSERT INTO `du;mmies` VALUES ('a;b', \"it\\'s;\"); -- c;d\n" +
+		"/* e;f */ SELECT 1 # g;h")
+	assert.Equal(t, []string{
+		"INSERT INTO `du;mmies` VALUES ('a;b', \"it\\'s;\")",
+		"-- c;d\n/* e;f */ SELECT 1 # g;h",
+	}, statements)
+
+	// Dashes start comments when followed by any whitespace
+	statements = persist.SplitStatements("SELECT 1--1;\nSELECT 2 --\tc;d\n;--")
+	assert.Equal(t, []string{"SELECT 1--1", "SELECT 2 --\tc;d", "--"}, statements)
+
+	assert.Len(t, persist.SplitStatements(" ; ;"), 0)
+}